@@ -14,20 +14,45 @@ import (
 )
 
 func addAddCommand(root *cobra.Command) {
+	var fromClipboard bool
+
 	cmd := &cobra.Command{
 		Use:   "add [name] [file]",
-		Short: "add a new entry by name, from editor, stdin, or a file",
+		Short: "add a new entry by name, from editor, stdin, a file, or the clipboard",
 		Args:  cobra.RangeArgs(0, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromClipboard {
+				return runAddFromClipboard(cmd, args)
+			}
 			if len(args) == 0 {
 				return runAddInteractive(cmd)
 			}
 			return runAddNamed(cmd, args)
 		},
 	}
+	cmd.Flags().BoolVar(&fromClipboard, "from-clipboard", false, "read content from the clipboard")
 	root.AddCommand(cmd)
 }
 
+func runAddFromClipboard(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("add --from-clipboard requires exactly one name")
+	}
+	store, err := app.EnsureStore()
+	if err != nil {
+		return err
+	}
+	name, err := app.NormalizeNewName(args[0])
+	if err != nil {
+		return err
+	}
+	text, err := readClipboard()
+	if err != nil {
+		return err
+	}
+	return saveEntry(cmd, store, name, strings.NewReader(text))
+}
+
 func runAddInteractive(cmd *cobra.Command) error {
 	store, err := app.EnsureStore()
 	if err != nil {
@@ -68,10 +93,13 @@ func runAddInteractive(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to read name: %w", err)
 	}
 
-	name := strings.TrimSpace(nameInput)
-	if name == "" {
+	if strings.TrimSpace(nameInput) == "" {
 		return fmt.Errorf("add aborted: empty name")
 	}
+	name, err := app.NormalizeNewName(strings.TrimSpace(nameInput))
+	if err != nil {
+		return err
+	}
 
 	return saveEntry(cmd, store, name, bytes.NewReader(data))
 }
@@ -82,7 +110,7 @@ func runAddNamed(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	name, err := app.NormalizeName(args[0])
+	name, err := app.NormalizeNewName(args[0])
 	if err != nil {
 		return err
 	}
@@ -107,7 +135,7 @@ func runAddNamed(cmd *cobra.Command, args []string) error {
 		} else {
 			// Ensure file exists before opening editor
 			if _, err := os.Stat(path); os.IsNotExist(err) {
-				if err := os.WriteFile(path, []byte{}, 0o644); err != nil {
+				if err := app.WriteFileAtomic(path, []byte{}, 0o644); err != nil {
 					return err
 				}
 			} else if err != nil {
@@ -127,16 +155,34 @@ func runAddNamed(cmd *cobra.Command, args []string) error {
 }
 
 func saveEntry(cmd *cobra.Command, store, name string, src io.Reader) error {
-	path, ext, err := app.TargetEntryPath(store, name)
+	data, err := io.ReadAll(src)
 	if err != nil {
 		return err
 	}
+	if err := writeEntry(cmd, store, name, data); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", name)
+	return nil
+}
 
-	existedBefore := app.FileExists(path)
-	data, err := io.ReadAll(src)
+// writeEntry stores data under name and commits it.
+func writeEntry(cmd *cobra.Command, store, name string, data []byte) error {
+	// Hold the store lock across the write and commit so concurrent
+	// invocations cannot interleave. The push that may follow the commit
+	// waits until the lock is released.
+	unlock, err := app.LockStore(store)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	path, ext, err := app.TargetEntryPath(store, name)
+	if err != nil {
+		return err
+	}
+
+	existedBefore := app.FileExists(path)
 
 	if len(bytes.TrimSpace(data)) == 0 {
 		if !existedBefore {
@@ -144,16 +190,16 @@ func saveEntry(cmd *cobra.Command, store, name string, src io.Reader) error {
 		}
 		return fmt.Errorf("add failed: empty content")
 	}
+	if err := app.CheckTokenBudget(store, name, data); err != nil {
+		return fmt.Errorf("add failed: %w", err)
+	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := app.WriteFileAtomic(path, data, 0o644); err != nil {
 		return err
 	}
 
 	// git add + commit (best-effort)
 	commitMsg := "feat: add " + name + ext
 	app.GitAddAndCommit(store, []string{name + ext}, commitMsg, cmd.ErrOrStderr())
-
-	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", name)
-
 	return nil
 }
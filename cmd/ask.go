@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addAskCommand(root *cobra.Command) {
+	var varPairs []string
+	var model string
+	var save string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "ask <name>",
+		Short: "send an entry to an OpenAI-compatible chat endpoint",
+		Long: "Render an entry and send it to the /chat/completions endpoint configured in the [llm]\n" +
+			"config table, streaming the reply to stdout. The entry's front matter may set 'model',\n" +
+			"'temperature' and a 'system' prompt; '## system', '## user' and '## assistant' headings\n" +
+			"split it into chat turns. Use --save to store the reply as a new entry; --force lets it\n" +
+			"overwrite an existing one.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			var saveName string
+			if save != "" {
+				if saveName, err = app.NormalizeNewName(save); err != nil {
+					return err
+				}
+				// Check before the request so a long answer isn't thrown away.
+				if _, _, err := app.ExistingEntryPath(store, saveName); err == nil && !force {
+					return fmt.Errorf("entry %s already exists (use --force to overwrite)", saveName)
+				}
+			}
+			vars, err := app.ParseVars(varPairs)
+			if err != nil {
+				return err
+			}
+			prompt, err := app.RenderEntry(store, args[0], "", vars)
+			if err != nil {
+				return err
+			}
+			file, err := app.ReadEntryFile(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			conf := app.GetLLMConfig()
+			req := app.ChatRequest{}
+			req.Model, req.Temperature, err = app.ChatOptions(file, conf)
+			if err != nil {
+				return err
+			}
+			if model != "" {
+				req.Model = model
+			}
+			if req.Model == "" {
+				return fmt.Errorf("no model configured: set 'model' in the [llm] config, the entry's front matter or --model")
+			}
+			if req.Messages, err = app.EntryMessages(file, prompt); err != nil {
+				return err
+			}
+
+			recordUsage(store, args[0], app.UsageAsk)
+			out := cmd.OutOrStdout()
+			reply, err := app.NewChatClient(conf).Stream(req, out)
+			if err != nil {
+				return err
+			}
+			if !strings.HasSuffix(reply, "\n") {
+				reply += "\n"
+				fmt.Fprintln(out)
+			}
+
+			if saveName != "" {
+				if err := writeEntry(cmd, store, saveName, []byte(reply)); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "✓ Saved reply as '%s'.\n", saveName)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&varPairs, "var", nil, "set a template variable (name=value, repeatable)")
+	cmd.Flags().StringVar(&model, "model", "", "model to use, overriding config and front matter")
+	cmd.Flags().StringVar(&save, "save", "", "save the reply as a new entry with this name")
+	cmd.Flags().BoolVar(&force, "force", false, "let --save overwrite an existing entry")
+	root.AddCommand(cmd)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"pea/internal/app"
+	"pea/platform"
+
+	"github.com/spf13/cobra"
+)
+
+// addClipboardClearCommand registers the hidden helper that scheduleClipboardClear
+// runs in the background to wipe sensitive text after a delay.
+func addClipboardClearCommand(root *cobra.Command) {
+	var after time.Duration
+	var digest string
+
+	cmd := &cobra.Command{
+		Use:    "clipboard-clear",
+		Short:  "clear the clipboard if it still holds the given text",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			time.Sleep(after)
+
+			cb, err := clipboard()
+			if err != nil {
+				return err
+			}
+			if err := cb.Init(); err != nil {
+				return err
+			}
+			current, err := cb.ReadText()
+			if errors.Is(err, platform.ErrReadUnsupported) {
+				// We cannot tell whether the user copied something else since.
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if textDigest(current) != digest {
+				return nil
+			}
+			return cb.WriteText("")
+		},
+	}
+	cmd.Flags().DurationVar(&after, "after", app.DefaultClipboardClear, "delay before clearing")
+	cmd.Flags().StringVar(&digest, "sha256", "", "hex SHA-256 of the text to clear")
+	_ = cmd.MarkFlagRequired("sha256")
+	root.AddCommand(cmd)
+}
+
+// scheduleClipboardClear starts a detached pea process that clears the
+// clipboard after the configured delay if it still holds text. Only a digest
+// of the text is passed on, so secrets never appear in the process list.
+// It returns the delay, or zero if clearing is disabled.
+func scheduleClipboardClear(text string) (time.Duration, error) {
+	after, err := app.GetClipboardClearConfig()
+	if err != nil || after == 0 {
+		return 0, err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("schedule clipboard clear: %w", err)
+	}
+	c := exec.Command(exe, "clipboard-clear", "--after", after.String(), "--sha256", textDigest(text))
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("schedule clipboard clear: %w", err)
+	}
+	return after, c.Process.Release()
+}
+
+// clearSensitiveCopy schedules clearing text, just copied through cb, from
+// the clipboard. The clear compares the clipboard with text, so when the
+// provider that took the copy cannot read it back, nothing is scheduled and
+// a warning is returned instead. It returns the delay, or zero if no clear
+// was scheduled.
+func clearSensitiveCopy(cb platform.Clipboard, text string) (time.Duration, string, error) {
+	after, err := app.GetClipboardClearConfig()
+	if err != nil || after == 0 {
+		return 0, "", err
+	}
+	if !platform.CanReadBack(cb) {
+		return 0, fmt.Sprintf("auto-clear is unavailable: %s cannot read the clipboard back", platform.WrittenBy(cb)), nil
+	}
+	after, err = scheduleClipboardClear(text)
+	return after, "", err
+}
+
+func textDigest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// isSensitiveEntry reports whether the named entry must not linger on the
+// clipboard.
+func isSensitiveEntry(store, name string) bool {
+	b, err := app.ReadEntryFile(store, name)
+	return err == nil && app.IsSensitive(b)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addCommitCommand(root *cobra.Command) {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "commit all uncommitted changes in the store",
+		Long:  "Stage every change in the store, including entries edited outside pea, and record them in a single commit.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			if !app.GitEnabled(store) {
+				return fmt.Errorf("commit failed: git is not enabled for this store")
+			}
+
+			st, err := app.GetStoreStatus(store)
+			if err != nil {
+				return err
+			}
+			msg := message
+			if msg == "" {
+				msg = storeCommitMessage(st)
+			}
+
+			committed, err := app.CommitStoreChanges(store, msg)
+			if err != nil {
+				return fmt.Errorf("commit failed: %w", err)
+			}
+			if !committed {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), "nothing to commit")
+				return err
+			}
+			app.PushAfterCommit(store, cmd.ErrOrStderr())
+
+			for _, c := range st.Changes {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-10s %s\n", c.State+":", c.Name)
+			}
+			for _, c := range st.Other {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-10s %s\n", c.State+":", c.File)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&message, "message", "m", "", "commit message")
+	root.AddCommand(cmd)
+}
+
+// storeCommitMessage describes everything 'git add -A' stages: the changed
+// entries and any other files, such as tests files or .pins.
+func storeCommitMessage(st app.StoreStatus) string {
+	entries, other := len(st.Changes), len(st.Other)
+	switch {
+	case other == 0:
+		return fmt.Sprintf("chore: commit %d changed %s", entries, plural(entries, "entry", "entries"))
+	case entries == 0:
+		return fmt.Sprintf("chore: commit %d changed %s", other, plural(other, "file", "files"))
+	}
+	return fmt.Sprintf("chore: commit %d changed %s and %d other %s",
+		entries, plural(entries, "entry", "entries"), other, plural(other, "file", "files"))
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,10 +12,10 @@ import (
 
 func addCompletionCommand(root *cobra.Command) {
 	cmd := &cobra.Command{
-		Use:       "completion [bash|zsh|install]",
+		Use:       "completion [bash|zsh|fish|powershell|nushell|install]",
 		Short:     "output or install shell completion",
 		Args:      cobra.MinimumNArgs(1),
-		ValidArgs: []string{"bash", "zsh", "install"},
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell", "nushell", "install"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			op := args[0]
 			switch op {
@@ -22,6 +23,12 @@ func addCompletionCommand(root *cobra.Command) {
 				return root.GenBashCompletion(cmd.OutOrStdout())
 			case "zsh":
 				return root.GenZshCompletion(cmd.OutOrStdout())
+			case "fish":
+				return root.GenFishCompletion(cmd.OutOrStdout(), true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(cmd.OutOrStdout())
+			case "nushell":
+				return genNushellCompletion(root, cmd.OutOrStdout())
 			case "install":
 				return runInstallCompletion(root)
 			default:
@@ -45,10 +52,17 @@ func runInstallCompletion(root *cobra.Command) error {
 
 	shell := filepath.Base(os.Getenv("SHELL"))
 
-	if shell == "zsh" {
+	switch shell {
+	case "zsh":
 		return installZsh(root, home, base)
-	} else if shell == "bash" {
+	case "bash":
 		return installBash(root, home, base)
+	case "fish":
+		return installFish(root, home)
+	case "pwsh", "powershell":
+		return installPowerShell(root, home, base)
+	case "nu":
+		return installNushell(root, home, base)
 	}
 
 	return installFallback(root, base, shell)
@@ -116,6 +130,146 @@ func installBash(root *cobra.Command, home, base string) error {
 	return nil
 }
 
+func installFish(root *cobra.Command, home string) error {
+	dir := filepath.Join(configHome(home), "fish", "completions")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create dir %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, "pea.fish")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := root.GenFishCompletion(f, true); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	// fish autoloads completions from this directory; no config change needed.
+	fmt.Printf("Installed completion script to %s\n", path)
+	fmt.Println("\nCompletion is available in new fish sessions.")
+
+	return nil
+}
+
+func installPowerShell(root *cobra.Command, home, base string) error {
+	path := filepath.Join(base, "pea.ps1")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := root.GenPowerShellCompletionWithDesc(f); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	fmt.Printf("Installed completion script to %s\n", path)
+
+	// Patch the current user's PowerShell profile
+	rcPath := filepath.Join(configHome(home), "powershell", "Microsoft.PowerShell_profile.ps1")
+	if err := os.MkdirAll(filepath.Dir(rcPath), 0o755); err != nil {
+		return fmt.Errorf("create dir %s: %w", filepath.Dir(rcPath), err)
+	}
+	cfgLine := fmt.Sprintf(". %s", path)
+
+	if err := updateShellConfig(rcPath, cfgLine); err != nil {
+		fmt.Printf("Warning: failed to update %s: %v\n", rcPath, err)
+	} else {
+		fmt.Printf("Updated %s\n", rcPath)
+	}
+	fmt.Printf("\nTo apply changes, run:\n  . %s\n", rcPath)
+
+	return nil
+}
+
+func installNushell(root *cobra.Command, home, base string) error {
+	path := filepath.Join(base, "pea.nu")
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := genNushellCompletion(root, f); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	fmt.Printf("Installed completion script to %s\n", path)
+
+	// Patch config.nu
+	rcPath := filepath.Join(configHome(home), "nushell", "config.nu")
+	if err := os.MkdirAll(filepath.Dir(rcPath), 0o755); err != nil {
+		return fmt.Errorf("create dir %s: %w", filepath.Dir(rcPath), err)
+	}
+	cfgLine := fmt.Sprintf("source %s", path)
+
+	if err := updateShellConfig(rcPath, cfgLine); err != nil {
+		fmt.Printf("Warning: failed to update %s: %v\n", rcPath, err)
+	} else {
+		fmt.Printf("Updated %s\n", rcPath)
+	}
+	fmt.Println("\nCompletion is available in new nushell sessions.")
+
+	return nil
+}
+
+// configHome returns $XDG_CONFIG_HOME, defaulting to ~/.config.
+func configHome(home string) string {
+	if v := os.Getenv("XDG_CONFIG_HOME"); v != "" {
+		return v
+	}
+	return filepath.Join(home, ".config")
+}
+
+// genNushellCompletion writes an external completer for nushell that asks
+// pea itself for candidates through cobra's hidden __complete command. The
+// completer is global in nushell, so it hands other commands to whichever
+// completer was configured before.
+func genNushellCompletion(root *cobra.Command, w io.Writer) error {
+	name := root.Name()
+	_, err := fmt.Fprintf(w, `# nushell completion for %[1]s
+#
+# Load it from config.nu:
+#   source /path/to/this/file
+#
+# This wraps the external completer for '%[1]s' only; other commands still go
+# to the completer that was set before this file was sourced, if any.
+
+def "%[1]s-completer" [spans: list<string>] {
+    ^%[1]s %[2]s ...($spans | skip 1)
+    | lines
+    | where {|line| not ($line | str starts-with ":") }
+    | each {|line|
+        let parts = ($line | split row "	")
+        if ($parts | length) > 1 {
+            {value: $parts.0, description: $parts.1}
+        } else {
+            {value: $parts.0}
+        }
+    }
+}
+
+let %[1]s_previous_completer = ($env.config.completions.external.completer? | default null)
+$env.config.completions.external.enable = true
+$env.config.completions.external.completer = {|spans|
+    if $spans.0 == "%[1]s" {
+        %[1]s-completer $spans
+    } else if $%[1]s_previous_completer != null {
+        do $%[1]s_previous_completer $spans
+    }
+}
+`, name, cobra.ShellCompRequestCmd)
+	return err
+}
+
 func installFallback(root *cobra.Command, base, shell string) error {
 	bashPath := filepath.Join(base, "pea.bash")
 	zshPath := filepath.Join(base, "_pea")
@@ -141,6 +295,7 @@ func installFallback(root *cobra.Command, base, shell string) error {
 	}
 
 	fmt.Printf("Unknown shell '%s'. Installed both scripts to %s\n", shell, base)
+	fmt.Printf("For fish, PowerShell or nushell run 'pea completion fish|powershell|nushell'.\n")
 	fmt.Printf("Add the relevant line to your config:\n")
 	fmt.Printf("Bash: source %s\n", bashPath)
 	fmt.Printf("Zsh:  fpath=(%s $fpath); autoload -U compinit; compinit\n", base)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -16,11 +17,113 @@ func completeNames(_ *cobra.Command, _ []string, toComplete string) ([]string, c
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
+	// Most frequently used entries first.
+	if usage, err := app.LoadUsage(store); err == nil {
+		_ = app.SortEntries(names, app.SortFrequent, usage)
+	}
 	var out []string
 	for _, n := range names {
 		if strings.HasPrefix(n, toComplete) || toComplete == "" {
 			out = append(out, n)
 		}
 	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeTags completes --tag values from the tags used across the store.
+func completeTags(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := app.EnsureStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	tags, err := app.AllTags(store)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var out []string
+	for t := range tags {
+		if strings.HasPrefix(strings.ToLower(t), strings.ToLower(toComplete)) {
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRevs completes --rev values from the history of the entry named
+// by the first argument, newest first.
+func completeRevs(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := app.EnsureStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	revs, err := app.EntryRevisions(store, args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, r := range revs {
+		if strings.HasPrefix(r, toComplete) {
+			out = append(out, r)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeRootArgs offers entry names, most used first, and aliases next to
+// the subcommands so that 'pea <name>' completes.
+func completeRootArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, directive := completeNames(cmd, args, toComplete)
+	if directive == cobra.ShellCompDirectiveError {
+		return nil, directive
+	}
+	var aliases []string
+	for alias := range app.ReadConfig().Aliases {
+		if strings.HasPrefix(alias, toComplete) && !isSubcommand(cmd.Root(), alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return append(names, aliases...), directive
+}
+
+// completePinned completes the names of pinned entries.
+func completePinned(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := app.EnsureStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	pins, err := app.LoadPins(store)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var out []string
+	for n := range pins {
+		if strings.HasPrefix(n, toComplete) {
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunners completes --runner values from the [runners] config table.
+func completeRunners(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var out []string
+	for name := range app.ReadConfig().Runners {
+		if strings.HasPrefix(name, toComplete) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
 	return out, cobra.ShellCompDirectiveNoFileComp
 }
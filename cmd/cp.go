@@ -3,12 +3,14 @@ package cmd
 import (
 	"fmt"
 	"pea/internal/app"
-	"pea/platform"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 func addCpCommand(root *cobra.Command) {
+	var varPairs []string
+
 	cmd := &cobra.Command{
 		Use:               "cp <name>",
 		Short:             "copy a snippet to the clipboard",
@@ -20,23 +22,41 @@ func addCpCommand(root *cobra.Command) {
 				return err
 			}
 
-			// Read content
-			b, err := app.ReadEntry(store, args[0], "")
+			vars, err := app.ParseVars(varPairs)
 			if err != nil {
 				return err
 			}
 
-			// Copy to clipboard
-			if err := platform.ClipboardImpl.Init(); err != nil {
-				return fmt.Errorf("clipboard init failed: %w", err)
+			// Read content
+			b, err := app.RenderEntry(store, args[0], "", vars)
+			if err != nil {
+				return err
 			}
-			if err := platform.ClipboardImpl.WriteText(string(b)); err != nil {
-				return fmt.Errorf("clipboard write failed: %w", err)
+
+			cb, err := copyToClipboard(string(b))
+			if err != nil {
+				return err
 			}
+			recordUsage(store, args[0], app.UsageCp)
 
-			fmt.Fprintf(cmd.ErrOrStderr(), "✓ Copied '%s' to clipboard.\n", args[0])
+			var after time.Duration
+			var warning string
+			if isSensitiveEntry(store, args[0]) {
+				if after, warning, err = clearSensitiveCopy(cb, string(b)); err != nil {
+					return err
+				}
+			}
+			if after > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "✓ Copied '%s' to clipboard (clears in %s).\n", args[0], after)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "✓ Copied '%s' to clipboard.\n", args[0])
+			}
+			if warning != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning)
+			}
 			return nil
 		},
 	}
+	cmd.Flags().StringArrayVar(&varPairs, "var", nil, "set a template variable (name=value, repeatable)")
 	root.AddCommand(cmd)
 }
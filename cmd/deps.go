@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"pea/internal/app"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func addDepsCommand(root *cobra.Command) {
+	var rev string
+
+	cmd := &cobra.Command{
+		Use:               "deps <name>",
+		Short:             "show the include graph of an entry",
+		Long:              "Show the entries pulled in by {{> name}} and {{> name@rev}} include directives, recursively.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			graph, err := app.IncludeGraph(store, args[0], rev)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), graph.Name)
+			printIncludeNodes(cmd.OutOrStdout(), graph.Children, 1)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&rev, "rev", "", "resolve includes at a specific git ref")
+	_ = cmd.RegisterFlagCompletionFunc("rev", completeRevs)
+	root.AddCommand(cmd)
+}
+
+func printIncludeNodes(w io.Writer, nodes []app.IncludeNode, depth int) {
+	for _, n := range nodes {
+		label := n.Name
+		if n.Rev != "" {
+			label += "@" + n.Rev
+		}
+		if n.Problem != "" {
+			label += " (" + n.Problem + ")"
+		}
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), label)
+		printIncludeNodes(w, n.Children, depth+1)
+	}
+}
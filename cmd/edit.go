@@ -11,17 +11,77 @@ import (
 )
 
 func addEditCommand(root *cobra.Command) {
+	var appendClipboard bool
+
 	cmd := &cobra.Command{
 		Use:   "edit [name]",
 		Short: "edit a snippet in $EDITOR",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if appendClipboard {
+				return runAppendClipboard(cmd, args[0])
+			}
 			return runEdit(cmd, args[0])
 		},
 	}
+	cmd.Flags().BoolVar(&appendClipboard, "append-clipboard", false, "append the clipboard to the entry instead of opening the editor")
 	root.AddCommand(cmd)
 }
 
+func runAppendClipboard(cmd *cobra.Command, nameRaw string) error {
+	store, err := app.EnsureStore()
+	if err != nil {
+		return err
+	}
+
+	name, err := app.NormalizeName(nameRaw)
+	if err != nil {
+		return err
+	}
+
+	text, err := readClipboard()
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace([]byte(text))) == 0 {
+		return fmt.Errorf("edit aborted: clipboard is empty")
+	}
+
+	unlock, err := app.LockStore(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path, ext, err := app.ExistingEntryPath(store, name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("entry not found: %s", name)
+		}
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read entry: %w", err)
+	}
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	content = append(content, text...)
+
+	if err := app.WriteFileAtomic(path, content, 0o644); err != nil {
+		return err
+	}
+
+	commitMsg := "feat: edit " + name + ext
+	app.GitAddAndCommit(store, []string{name + ext}, commitMsg, cmd.ErrOrStderr())
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", name)
+
+	return nil
+}
+
 func runEdit(cmd *cobra.Command, nameRaw string) error {
 	store, err := app.EnsureStore()
 	if err != nil {
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addExportCommand(root *cobra.Command) {
+	var output string
+	var dryRun bool
+	var sel selectorFlags
+
+	cmd := &cobra.Command{
+		Use:   "export [name|glob...] -o <file.tar.gz>",
+		Short: "export entries to a tar.gz archive",
+		Long: "Write entries, front matter included, to a gzip-compressed tar archive. Select them\n" +
+			"by name or glob and narrow with --tag and --query; with no selection every entry is\n" +
+			"exported. Use -o - to write the archive to stdout.",
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return fmt.Errorf("missing --output: give a file name, or - for stdout")
+			}
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			var names []string
+			if len(args) == 0 && !sel.isSet() {
+				names, err = app.ListEntries(store)
+			} else {
+				names, err = selectEntries(store, sel.selector(args))
+			}
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("no entries to export")
+			}
+			if dryRun {
+				for _, name := range names {
+					if _, err := fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would export %s\n", name); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if output == "-" {
+				return app.ExportEntries(store, names, cmd.OutOrStdout())
+			}
+			// Write through a temporary file so a failed export leaves no
+			// truncated archive behind.
+			err = app.WriteAtomic(output, 0o644, func(w io.Writer) error {
+				return app.ExportEntries(store, names, w)
+			})
+			if err != nil {
+				return fmt.Errorf("export failed: %w", err)
+			}
+			cmd.PrintErrf("✓ Exported %d entries to %s\n", len(names), output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "archive to write, or - for stdout")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the entries without writing the archive")
+	sel.register(cmd)
+	root.AddCommand(cmd)
+}
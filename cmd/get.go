@@ -1,6 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"pea/internal/app"
 	"pea/platform"
 
@@ -9,10 +17,20 @@ import (
 
 func addGetCommand(root *cobra.Command) {
 	var rev string
+	var raw bool
+	var varPairs []string
+	var stats bool
+	var format string
+	var watch bool
 
 	cmd := &cobra.Command{
-		Use:               "get <name>",
-		Short:             "retrieve a snippet",
+		Use:   "get <name>",
+		Short: "retrieve a snippet",
+		Long: "Print an entry with includes expanded and template variables filled in, copying it to\n" +
+			"the clipboard when stdout is a terminal.\n\n" +
+			"--stats counts tokens with the entry's model encoding. pea does not ship the tiktoken\n" +
+			"rank files: put e.g. cl100k_base.tiktoken in ~/.pea/tokenizers for exact counts, or an\n" +
+			"approximation is used.",
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: completeNames,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -20,8 +38,41 @@ func addGetCommand(root *cobra.Command) {
 			if err != nil {
 				return err
 			}
+			if watch && rev != "" {
+				return fmt.Errorf("--watch cannot be combined with --rev")
+			}
+
+			render := func() ([]byte, error) {
+				var b []byte
+				var err error
+				if raw {
+					b, err = app.ReadEntryRaw(store, args[0], rev)
+				} else {
+					vars, verr := app.ParseVars(varPairs)
+					if verr != nil {
+						return nil, verr
+					}
+					b, err = app.RenderEntry(store, args[0], rev, vars)
+				}
+				if err != nil {
+					return nil, err
+				}
+				if !stats && format == formatText {
+					return b, nil
+				}
+				// Front matter (roles, model) comes from the same revision
+				// as the content.
+				file, err := app.ReadEntryFileAt(store, args[0], rev)
+				if err != nil {
+					return nil, err
+				}
+				if stats {
+					return statsText(file, b), nil
+				}
+				return formatEntry(file, b, format)
+			}
 
-			b, err := app.ReadEntry(store, args[0], rev)
+			b, err := render()
 			if err != nil {
 				return err
 			}
@@ -31,12 +82,17 @@ func addGetCommand(root *cobra.Command) {
 			if err != nil {
 				return err
 			}
+			if !stats {
+				recordUsage(store, args[0], app.UsageGet)
+			}
+
+			if watch {
+				return watchEntry(cmd, store, args[0], b, stats, render)
+			}
 
 			// If stdout is a TTY, copy to clipboard
-			if isTTY() {
-				if err := copyToClipboard(string(b)); err != nil {
-					cmd.PrintErrf("warning: failed to copy to clipboard: %v\n", err)
-				}
+			if isTTY() && !stats {
+				copyEntry(cmd, store, args[0], b)
 			}
 
 			return nil
@@ -44,16 +100,143 @@ func addGetCommand(root *cobra.Command) {
 	}
 
 	cmd.Flags().StringVar(&rev, "rev", "", "read entry content from a specific git ref")
+	cmd.Flags().BoolVar(&raw, "raw", false, "print without expanding includes or template variables")
+	cmd.Flags().StringArrayVar(&varPairs, "var", nil, "set a template variable (name=value, repeatable)")
+	cmd.Flags().BoolVar(&stats, "stats", false, "print character, word and token counts instead of the content")
+	cmd.Flags().StringVar(&format, "format", formatText, "output format: text or messages-json")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep running and re-render whenever the entry or its includes change")
+	_ = cmd.RegisterFlagCompletionFunc("rev", completeRevs)
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{formatText, formatMessagesJSON}, cobra.ShellCompDirectiveNoFileComp))
 	root.AddCommand(cmd)
 }
 
-func copyToClipboard(s string) error {
-	// Use platform clipboard abstraction
-	if err := platform.ClipboardImpl.Init(); err != nil {
-		return err
+// Output formats for 'pea get --format'.
+const (
+	formatText         = "text"
+	formatMessagesJSON = "messages-json"
+)
+
+// formatEntry converts rendered content b of the entry file to the requested
+// format. messages-json splits it at role headings into a chat messages
+// array.
+func formatEntry(file, b []byte, format string) ([]byte, error) {
+	switch format {
+	case formatText:
+		return b, nil
+	case formatMessagesJSON:
+		msgs, err := app.EntryMessages(file, b)
+		if err != nil {
+			return nil, err
+		}
+		out, err := json.MarshalIndent(msgs, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+	return nil, fmt.Errorf("invalid format %q: use text or messages-json", format)
+}
+
+// statsText reports the size of rendered content b of the entry file.
+func statsText(file, b []byte) []byte {
+	st := app.MeasureText(string(b), app.TokenizerFor(file))
+	tokenizer := st.Tokenizer
+	if st.Fallback != "" {
+		tokenizer += "; " + missingRankFile(st.Fallback)
+	}
+	return fmt.Appendf(nil, "chars:  %d\nwords:  %d\ntokens: %d (%s)\n", st.Chars, st.Words, st.Tokens, tokenizer)
+}
+
+// copyEntry copies rendered entry content to the clipboard, scheduling a
+// clear for sensitive entries. Failures are reported as warnings.
+func copyEntry(cmd *cobra.Command, store, name string, b []byte) {
+	cb, err := copyToClipboard(string(b))
+	if err != nil {
+		cmd.PrintErrf("warning: failed to copy to clipboard: %v\n", err)
+		return
+	}
+	if !isSensitiveEntry(store, name) {
+		return
+	}
+	_, warning, err := clearSensitiveCopy(cb, string(b))
+	if err != nil {
+		cmd.PrintErrf("warning: %v\n", err)
+	} else if warning != "" {
+		cmd.PrintErrf("warning: %s\n", warning)
+	}
+}
+
+// watchEntry re-renders an entry whenever it or one of its includes changes,
+// printing the result and, like get, copying it to the clipboard when stdout
+// is a terminal, until interrupted. Render errors, e.g. from a half-written
+// include, are reported and the watch continues.
+func watchEntry(cmd *cobra.Command, store, name string, b []byte, stats bool, render func() ([]byte, error)) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	copyRenders := isTTY() && !stats
+	if copyRenders {
+		copyEntry(cmd, store, name, b)
+	}
+	cmd.PrintErrf("watching %s (Ctrl-C to stop)\n", name)
+	return app.WatchEntry(ctx, store, name, app.DefaultWatchDebounce, func() {
+		b, err := render()
+		if err != nil {
+			cmd.PrintErrf("%s warning: %v\n", time.Now().Format(time.TimeOnly), err)
+			return
+		}
+		cmd.PrintErrf("%s updated %s\n", time.Now().Format(time.TimeOnly), name)
+		if _, err := cmd.OutOrStdout().Write(b); err != nil {
+			cmd.PrintErrf("warning: %v\n", err)
+		}
+		if copyRenders {
+			copyEntry(cmd, store, name, b)
+		}
+	})
+}
+
+// copyToClipboard writes s to the configured clipboard and returns the
+// provider it went through, for clearSensitiveCopy.
+func copyToClipboard(s string) (platform.Clipboard, error) {
+	cb, err := clipboard()
+	if err != nil {
+		return nil, err
 	}
+	if err := cb.Init(); err != nil {
+		return nil, fmt.Errorf("clipboard init failed: %w", err)
+	}
+	if err := cb.WriteText(s); err != nil {
+		return nil, fmt.Errorf("clipboard write failed: %w", err)
+	}
+	return cb, nil
+}
+
+// readClipboard returns the current clipboard text.
+func readClipboard() (string, error) {
+	cb, err := clipboard()
+	if err != nil {
+		return "", err
+	}
+	if err := cb.Init(); err != nil {
+		return "", fmt.Errorf("clipboard init failed: %w", err)
+	}
+	text, err := cb.ReadText()
+	if errors.Is(err, platform.ErrReadUnsupported) {
+		return "", fmt.Errorf("clipboard read failed: the active clipboard backend cannot read; set clipboard in config to a backend that can")
+	}
+	if err != nil {
+		return "", fmt.Errorf("clipboard read failed: %w", err)
+	}
+	return text, nil
+}
 
-	return platform.ClipboardImpl.WriteText(s)
+// clipboard returns the clipboard provider selected by config, falling back
+// to the platform default.
+func clipboard() (platform.Clipboard, error) {
+	if name := app.GetClipboardConfig(); name != "" {
+		return platform.NewClipboard(name)
+	}
+	return platform.ClipboardImpl, nil
 }
 
 // Helper to access internal read functionality if needed by other commands (like root)
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit statuses of 'pea lint', so hooks and CI can tell findings apart from
+// a lint that could not run.
+const (
+	lintExitErrors   = 1 // errors found
+	lintExitWarnings = 2 // only warnings found, with --strict
+	lintExitUsage    = 3 // bad flags or names, or the store could not be read
+)
+
+type lintReport struct {
+	Checked  int               `json:"checked"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+	Problems []app.LintProblem `json:"problems"`
+}
+
+func addLintCommand(root *cobra.Command) {
+	var asJSON bool
+	var strict bool
+	var maxBytes int64
+	var staged bool
+
+	cmd := &cobra.Command{
+		Use:   "lint [names...]",
+		Short: "check entries for malformed front matter, broken includes and other problems",
+		Long: "Check entries (all of them when no names are given) for unclosed or malformed front matter,\n" +
+			".md/.txt pairs that shadow each other, template variables missing from 'vars:', broken\n" +
+			"includes, oversize entries and trailing whitespace.\n\n" +
+			"Names may be given as file names (notes.md), and --staged lints the content staged in git,\n" +
+			"which makes 'pea lint --staged' usable from a pre-commit hook.\n\n" +
+			"Exit status: 0 when clean, 1 when errors are found, 2 when only warnings are found with\n" +
+			"--strict, and 3 when lint could not run (bad flags or names, unreadable store).",
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return &exitError{lintExitUsage, err}
+			}
+
+			checked, problems, err := app.LintEntries(store, args, app.LintOptions{MaxBytes: maxBytes, Staged: staged})
+			if err != nil {
+				return &exitError{lintExitUsage, err}
+			}
+			report := lintReport{Checked: checked, Problems: problems}
+			for _, p := range problems {
+				if p.Severity == app.SeverityError {
+					report.Errors++
+				} else {
+					report.Warnings++
+				}
+			}
+			if report.Problems == nil {
+				report.Problems = []app.LintProblem{}
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				for _, p := range problems {
+					loc := p.File
+					if p.Line > 0 {
+						loc = fmt.Sprintf("%s:%d", p.File, p.Line)
+					}
+					fmt.Fprintf(out, "%s: %s: %s [%s]\n", loc, p.Severity, p.Message, p.Check)
+				}
+				if len(problems) == 0 {
+					fmt.Fprintf(out, "checked %d entries: no problems\n", checked)
+				}
+			}
+
+			if report.Errors > 0 || (strict && report.Warnings > 0) {
+				code := lintExitErrors
+				if report.Errors == 0 {
+					code = lintExitWarnings
+				}
+				return &exitError{code, fmt.Errorf("lint failed: %d errors, %d warnings in %d entries", report.Errors, report.Warnings, checked)}
+			}
+			return nil
+		},
+	}
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &exitError{lintExitUsage, err}
+	})
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print a JSON report")
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail on warnings as well as errors")
+	cmd.Flags().BoolVar(&staged, "staged", false, "lint the content staged in git instead of the working tree")
+	cmd.Flags().Int64Var(&maxBytes, "max-bytes", app.DefaultLintMaxBytes, "warn about entries larger than this many bytes")
+	root.AddCommand(cmd)
+}
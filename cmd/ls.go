@@ -3,30 +3,153 @@ package cmd
 import (
 	"fmt"
 	"pea/internal/app"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
 
 func addListCommand(root *cobra.Command) {
+	var sortBy string
+	var long bool
+	var pinned bool
+	var tags []string
+
 	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "list stored entries",
+		Long: "List entry names, pinned entries first; collections are marked. Use -l for tags,\n" +
+			"description, size, token count, last modification and last commit author, --tag to\n" +
+			"filter by tags and --pinned to show only pinned entries.\n\n" +
+			"Token counts are exact only with the encoding's tiktoken rank file, which pea does not\n" +
+			"ship, in ~/.pea/tokenizers (e.g. cl100k_base.tiktoken); approximate counts show a ~.",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			store, err := app.EnsureStore()
 			if err != nil {
 				return err
 			}
-			entries, err := app.ListEntries(store)
+			infos, err := app.ListEntryInfo(store, long)
 			if err != nil {
 				return err
 			}
+
+			var entries []app.EntryInfo
+			for _, e := range infos {
+				if pinned && !e.Pinned {
+					continue
+				}
+				if !app.HasAllTags(e.Tags, tags) {
+					continue
+				}
+				entries = append(entries, e)
+			}
+
+			var usage map[string]app.UsageStats
+			if sortBy == app.SortRecent || sortBy == app.SortFrequent {
+				if usage, err = app.LoadUsage(store); err != nil {
+					return err
+				}
+			}
+			if err := app.SortEntryInfo(entries, sortBy, usage); err != nil {
+				return err
+			}
+
+			if long {
+				return printLongList(cmd, entries)
+			}
+			// Only mark collections for people; scripts get bare names.
+			tty := isTTY()
 			for _, e := range entries {
-				if _, err := fmt.Fprintln(cmd.OutOrStdout(), e); err != nil {
+				name := e.Name
+				if tty {
+					name = displayName(e)
+				}
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), name); err != nil {
 					return err
 				}
 			}
 			return nil
 		},
 	}
+	cmd.Flags().StringVar(&sortBy, "sort", app.SortName, "order by name, recent, frequent, modified or size")
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "show metadata columns")
+	cmd.Flags().BoolVar(&pinned, "pinned", false, "only list pinned entries")
+	cmd.Flags().StringArrayVar(&tags, "tag", nil, "filter by tag (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{app.SortName, app.SortRecent, app.SortFrequent, app.SortModified, app.SortSize}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags)
 	root.AddCommand(cmd)
 }
+
+// maxDescription bounds the description column of 'pea ls -l'.
+const maxDescription = 40
+
+func printLongList(cmd *cobra.Command, entries []app.EntryInfo) error {
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tTAGS\tDESCRIPTION\tSIZE\tTOKENS\tMODIFIED\tAUTHOR")
+	var fallbacks []string
+	for _, e := range entries {
+		mark := " "
+		if e.Pinned {
+			mark = "*"
+		}
+		desc := e.Description
+		if r := []rune(desc); len(r) > maxDescription {
+			desc = string(r[:maxDescription-1]) + "…"
+		}
+		tokens := strconv.Itoa(e.Tokens)
+		if e.TokenFallback != "" {
+			tokens = "~" + tokens
+			if !slices.Contains(fallbacks, e.TokenFallback) {
+				fallbacks = append(fallbacks, e.TokenFallback)
+			}
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			mark, displayName(e),
+			orDash(strings.Join(e.Tags, ",")),
+			orDash(desc),
+			humanSize(e.Size),
+			tokens,
+			e.Modified.Format("2006-01-02 15:04"),
+			orDash(e.Author))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, enc := range fallbacks {
+		fmt.Fprintf(cmd.ErrOrStderr(), "note: token counts marked ~ are approximate: %s\n", missingRankFile(enc))
+	}
+	return nil
+}
+
+// missingRankFile explains why the approximation stands in for encoding.
+func missingRankFile(encoding string) string {
+	return fmt.Sprintf("%s.tiktoken not found in %s", encoding, app.TokenizerDir())
+}
+
+// displayName is the entry name as listed, marking collections.
+func displayName(e app.EntryInfo) string {
+	if e.Collection {
+		return e.Name + " (collection)"
+	}
+	return e.Name
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func humanSize(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1fK", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1fM", float64(n)/(1024*1024))
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addMigrateCommand(root *cobra.Command) {
+	var dryRun bool
+	var merge bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "convert legacy .txt entries to .md in one commit",
+		Long: "Rename legacy .txt entries to .md and normalize the front matter of every entry. When both\n" +
+			"name.txt and name.md exist, an identical .txt is dropped; a differing one is reported as a\n" +
+			"conflict and left alone unless --merge is given, which appends it to the .md entry and\n" +
+			"combines tags. All changes are recorded as a single commit; if anything fails, including\n" +
+			"the commit, the store is left as it was. Use --dry-run to see the plan first.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			plan, err := app.PlanMigration(store, merge)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(plan) == 0 {
+				_, err := fmt.Fprintln(out, "nothing to migrate")
+				return err
+			}
+			for _, step := range plan {
+				fmt.Fprintln(out, step)
+			}
+			if dryRun {
+				_, err := fmt.Fprintln(out, "dry-run: no changes made")
+				return err
+			}
+
+			migrated, err := app.ApplyMigration(store, plan, cmd.ErrOrStderr())
+			if err != nil {
+				return fmt.Errorf("migrate failed: %w", err)
+			}
+			_, err = fmt.Fprintf(out, "migrated %d legacy entries\n", migrated)
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the plan without changing anything")
+	cmd.Flags().BoolVar(&merge, "merge", false, "merge differing .txt/.md pairs instead of reporting them")
+	root.AddCommand(cmd)
+}
@@ -1,24 +1,49 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"pea/internal/app"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+// rename is one entry moved by 'pea mv'.
+type rename struct {
+	oldName, newName string
+	ext              string
+	oldPath, newPath string
+}
+
+func (r rename) String() string {
+	return fmt.Sprintf("%s%s to %s%s", r.oldName, r.ext, r.newName, r.ext)
+}
+
 func addMoveCommand(root *cobra.Command) {
 	var choreRename bool
 	var confirm bool
 	var dryRun bool
+	var noUpdateRefs bool
+	var sel selectorFlags
 
 	cmd := &cobra.Command{
-		Use:   "mv <old> <new>",
-		Short: "rename an entry",
-		Args:  cobra.ExactArgs(2),
+		Use:   "mv <old> <new> | mv <name|glob>... <folder>/",
+		Short: "rename entries",
+		Long: "Rename an entry and, in the same commit, every reference to it: include directives,\n" +
+			"collection members and the pins list. Aliases in the config are updated too. Includes\n" +
+			"pinned to a revision keep the old name. Use --dry-run to preview the files that change.\n\n" +
+			"A destination ending in / moves entries into a folder. The store is flat, so the folder\n" +
+			"becomes a name prefix: 'pea mv draft_x archive/' renames draft_x to archive_draft_x.\n" +
+			"Select several entries with globs, --tag or --query; they move in a single commit.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 && !(len(args) == 1 && sel.isSet()) {
+				return fmt.Errorf("requires a source and a destination")
+			}
+			return nil
+		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) >= 1 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
@@ -30,53 +55,217 @@ func addMoveCommand(root *cobra.Command) {
 			if err != nil {
 				return err
 			}
-			oldName, err := app.NormalizeName(args[0])
+			renames, err := planRenames(store, args[:len(args)-1], args[len(args)-1], sel)
 			if err != nil {
 				return err
 			}
-			newName, err := app.NormalizeName(args[1])
-			if err != nil {
-				return err
-			}
-			oldPath, ext, err := app.ExistingEntryPath(store, oldName)
-			if err != nil {
-				if os.IsNotExist(err) {
-					return fmt.Errorf("rename failed: not found: %s", oldName)
+			// Plan every reference update up front, as if all renames were
+			// done, so nothing touches disk until the whole move is known to
+			// work and references between the moved entries are rewritten too.
+			var updates []app.RefUpdate
+			if !noUpdateRefs {
+				pairs := make(map[string]string, len(renames))
+				for _, r := range renames {
+					pairs[r.oldName] = r.newName
+				}
+				if updates, err = app.PlanRenameRefs(store, pairs); err != nil {
+					return fmt.Errorf("rename failed: %w", err)
 				}
-				return fmt.Errorf("rename failed: %w", err)
-			}
-			newPath := app.DefaultEntryPath(store, newName)
-			if ext == app.LegacyExt {
-				newPath = app.LegacyEntryPath(store, newName)
 			}
 			if dryRun {
-				_, err := fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would rename %s%s to %s%s\n", oldName, ext, newName, ext)
-				return err
+				out := cmd.OutOrStdout()
+				for _, r := range renames {
+					fmt.Fprintf(out, "dry-run: would rename %s\n", r)
+					if oldTests, newTests := app.TestsFile(store, r.oldName), app.TestsFile(store, r.newName); app.FileExists(oldTests) {
+						fmt.Fprintf(out, "dry-run: would rename %s to %s\n", filepath.Base(oldTests), filepath.Base(newTests))
+					}
+				}
+				for _, u := range updates {
+					fmt.Fprintf(out, "dry-run: would update %s\n", u.Describe())
+				}
+				return nil
 			}
 			if confirm {
-				fmt.Fprintf(cmd.OutOrStdout(), "Rename %s%s to %s%s? [y/N]: ", oldName, ext, newName, ext)
-				reader := bufio.NewReader(cmd.InOrStdin())
-				ans, _ := reader.ReadString('\n')
-				ans = strings.ToLower(strings.TrimSpace(ans))
-				if ans != "y" && ans != "yes" {
+				question := fmt.Sprintf("Rename %s?", renames[0])
+				if len(renames) > 1 {
+					for _, r := range renames {
+						fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", r)
+					}
+					question = fmt.Sprintf("Rename %d entries?", len(renames))
+				}
+				if !askConfirm(cmd, question) {
 					return fmt.Errorf("rename aborted")
 				}
 			}
-			if err := os.Rename(oldPath, newPath); err != nil {
-				return fmt.Errorf("rename failed: %w", err)
+			unlock, err := app.LockStore(store)
+			if err != nil {
+				return err
 			}
-			// git add new and commit (best-effort)
-			commitMsg := fmt.Sprintf("refactor: rename %s%s to %s%s", oldName, ext, newName, ext)
+			defer unlock()
+
+			verb := "refactor"
 			if choreRename {
-				commitMsg = fmt.Sprintf("chore: rename %s%s to %s%s", oldName, ext, newName, ext)
+				verb = "chore"
 			}
-			app.GitAddAndCommit(store, []string{oldName + ext, newName + ext}, commitMsg, cmd.ErrOrStderr())
-			_, err = fmt.Fprintln(cmd.OutOrStdout(), newName)
-			return err
+			commitMsg := fmt.Sprintf("%s: rename %s", verb, renames[0])
+			if len(renames) > 1 {
+				commitMsg = fmt.Sprintf("%s: move %d entries to %s/", verb, len(renames), strings.TrimSuffix(args[len(args)-1], "/"))
+			}
+			if err := applyRenames(store, renames, updates, commitMsg, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			// The config lives outside the store and is not versioned, so it
+			// only changes once the store commit has succeeded.
+			for _, u := range updates {
+				if u.File != "" {
+					cmd.PrintErrf("updated %s\n", u.Describe())
+					continue
+				}
+				if err := app.WriteFileAtomic(u.Path, u.Content, 0o644); err != nil {
+					return fmt.Errorf("update %s: %w", u.Describe(), err)
+				}
+				cmd.PrintErrf("updated %s\n", u.Describe())
+			}
+			for _, r := range renames {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), r.newName); err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&choreRename, "chore", false, "mark rename as organizational")
 	cmd.Flags().BoolVar(&confirm, "confirm", false, "prompt before renaming")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would happen without renaming")
+	cmd.Flags().BoolVar(&noUpdateRefs, "no-update-refs", false, "leave includes, collections, pins and aliases pointing at the old name")
+	sel.register(cmd)
 	root.AddCommand(cmd)
 }
+
+// planRenames resolves the entries to move and their new names. A plain
+// destination renames a single entry; a destination ending in / prefixes
+// every selected entry with the folder name.
+func planRenames(store string, sources []string, dest string, sel selectorFlags) ([]rename, error) {
+	folder, isFolder := strings.CutSuffix(dest, "/")
+	target, err := app.NormalizeName(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if isFolder {
+		if names, err = selectEntries(store, sel.selector(sources)); err != nil {
+			return nil, fmt.Errorf("rename failed: %w", err)
+		}
+	} else {
+		if len(sources) != 1 || app.IsGlob(sources[0]) || sel.isSet() {
+			return nil, fmt.Errorf("moving several entries needs a folder destination such as %s/", target)
+		}
+		name, err := app.NormalizeName(sources[0])
+		if err != nil {
+			return nil, err
+		}
+		names = []string{name}
+	}
+
+	var renames []rename
+	for _, name := range names {
+		newName := target
+		if isFolder {
+			if strings.HasPrefix(name, target+"_") {
+				continue // already in the folder
+			}
+			newName = target + "_" + name
+		}
+		if _, err := app.NormalizeNewName(newName); err != nil {
+			return nil, fmt.Errorf("rename failed: %w", err)
+		}
+		oldPath, ext, err := app.ExistingEntryPath(store, name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("rename failed: not found: %s", name)
+			}
+			return nil, fmt.Errorf("rename failed: %w", err)
+		}
+		if _, _, err := app.ExistingEntryPath(store, newName); err == nil {
+			return nil, fmt.Errorf("rename failed: %s already exists", newName)
+		}
+		newPath := app.DefaultEntryPath(store, newName)
+		if ext == app.LegacyExt {
+			newPath = app.LegacyEntryPath(store, newName)
+		}
+		renames = append(renames, rename{oldName: name, newName: newName, ext: ext, oldPath: oldPath, newPath: newPath})
+	}
+	if len(renames) == 0 {
+		return nil, fmt.Errorf("nothing to move: every selected entry is already in %s/", target)
+	}
+	return renames, nil
+}
+
+// applyRenames moves the entries and their tests files, writes the store
+// files in updates and commits the result. If any step fails, everything
+// done so far is undone so the store is left as it was.
+func applyRenames(store string, renames []rename, updates []app.RefUpdate, commitMsg string, stderr io.Writer) (err error) {
+	var undo []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}()
+	move := func(from, to string) error {
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("rename failed: %w", err)
+		}
+		undo = append(undo, func() { _ = os.Rename(to, from) })
+		return nil
+	}
+
+	var paths []string
+	for _, r := range renames {
+		if err := move(r.oldPath, r.newPath); err != nil {
+			return err
+		}
+		// An untracked entry or tests file leaves nothing to stage at the
+		// old path.
+		if app.IsTracked(store, r.oldName+r.ext) {
+			paths = append(paths, r.oldName+r.ext)
+		}
+		paths = append(paths, r.newName+r.ext)
+		if oldTests, newTests := app.TestsFile(store, r.oldName), app.TestsFile(store, r.newName); app.FileExists(oldTests) {
+			if err := move(oldTests, newTests); err != nil {
+				return err
+			}
+			if app.IsTracked(store, filepath.Base(oldTests)) {
+				paths = append(paths, filepath.Base(oldTests))
+			}
+			paths = append(paths, filepath.Base(newTests))
+		}
+	}
+	for _, u := range updates {
+		if u.File == "" {
+			continue
+		}
+		old, err := os.ReadFile(u.Path)
+		existed := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("update %s: %w", u.Describe(), err)
+		}
+		if err := app.WriteFileAtomic(u.Path, u.Content, 0o644); err != nil {
+			return fmt.Errorf("update %s: %w", u.Describe(), err)
+		}
+		path := u.Path
+		if !existed {
+			undo = append(undo, func() { _ = os.Remove(path) })
+		} else {
+			undo = append(undo, func() { _ = app.WriteFileAtomic(path, old, 0o644) })
+		}
+		paths = append(paths, u.File)
+	}
+	if err := app.CommitPaths(store, paths, commitMsg, stderr); err != nil {
+		return fmt.Errorf("rename failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addPinCommands(root *cobra.Command) {
+	pin := &cobra.Command{
+		Use:               "pin <name>",
+		Short:             "mark an entry as a favorite",
+		Long:              "Pinned entries are listed first by 'pea ls' and shown alone by 'pea ls --pinned'.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPinned(cmd, args[0], true)
+		},
+	}
+	unpin := &cobra.Command{
+		Use:               "unpin <name>",
+		Short:             "remove an entry from the favorites",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePinned,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPinned(cmd, args[0], false)
+		},
+	}
+	root.AddCommand(pin, unpin)
+}
+
+func setPinned(cmd *cobra.Command, name string, pinned bool) error {
+	store, err := app.EnsureStore()
+	if err != nil {
+		return err
+	}
+	changed, err := app.SetPinned(store, name, pinned, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	switch {
+	case !changed && pinned:
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s is already pinned\n", name)
+	case !changed:
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s is not pinned\n", name)
+	case pinned:
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "pinned %s\n", name)
+	default:
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "unpinned %s\n", name)
+	}
+	return err
+}
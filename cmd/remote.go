@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"pea/internal/app"
 	"strings"
 
@@ -15,7 +14,7 @@ func addRemoteCommand(root *cobra.Command) {
 	remoteCmd := &cobra.Command{
 		Use:   "remote <url> | create <name>",
 		Short: "Configure a remote git repository",
-		Long:  "Configure a remote git repository to sync your prompts.\nProvide a URL to set an existing remote, or use 'create' to make a new one via GitHub CLI.",
+		Long:  "Configure a remote git repository to sync your prompts.\nProvide a URL to set an existing remote, or use 'create' to make a new one on GitHub, GitLab, Gitea or as a bare repository.",
 		Args:  cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
@@ -28,12 +27,18 @@ func addRemoteCommand(root *cobra.Command) {
 
 	createCmd := &cobra.Command{
 		Use:   "create <name>",
-		Short: "Create a new GitHub repository via 'gh' CLI",
-		Long:  "Create a new repository on GitHub using the 'gh' CLI and configure it as the remote.\nRequires 'gh' to be installed and authenticated.",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runCreateRemote,
+		Short: "Create a new remote repository and link it",
+		Long: "Create a new repository and configure it as the remote.\n\n" +
+			"Providers (select with --provider or [remote] provider in config):\n" +
+			"  github  GitHub via the 'gh' CLI (default; requires 'gh auth login')\n" +
+			"  gitlab  GitLab via its API (token) or the 'glab' CLI\n" +
+			"  gitea   Gitea/Forgejo via its API (url and token)\n" +
+			"  bare    bare repository under [remote] path, locally or over ssh",
+		Args: cobra.ExactArgs(1),
+		RunE: runCreateRemote,
 	}
 	createCmd.Flags().Bool("public", false, "make the new repository public (default: private)")
+	createCmd.Flags().String("provider", "", "remote provider: github, gitlab, gitea or bare (default from config)")
 
 	remoteCmd.AddCommand(createCmd)
 	root.AddCommand(remoteCmd)
@@ -59,29 +64,26 @@ func runSetRemote(cmd *cobra.Command, url string) error {
 func runCreateRemote(cmd *cobra.Command, args []string) error {
 	repoName := args[0]
 	isPublic, _ := cmd.Flags().GetBool("public")
+	providerName, _ := cmd.Flags().GetString("provider")
 
-	// 1. Check gh installed
-	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("GitHub CLI 'gh' not found. Please install it: https://cli.github.com/")
+	provider, err := app.NewRemoteProvider(providerName, app.ReadConfig().Remote)
+	if err != nil {
+		return err
 	}
+	spec := app.RepoSpec{Name: repoName, Public: isPublic}
 
-	// 2. Check login status
-	out, err := exec.Command("gh", "api", "user", "--jq", ".login").Output()
+	// 1. Check the provider is usable and resolve where the repo goes
+	target, err := provider.Target(spec)
 	if err != nil {
-		return fmt.Errorf("failed to check GitHub login status. Run 'gh auth login' first.\nError: %w", err)
-	}
-	user := strings.TrimSpace(string(out))
-	if user == "" {
-		return fmt.Errorf("not logged in to GitHub. Run 'gh auth login' first.")
+		return err
 	}
 
-	// 3. Confirm
+	// 2. Confirm
 	visibility := "private"
 	if isPublic {
 		visibility = "public"
 	}
-	fmt.Printf("Logged in as: %s\n", user)
-	fmt.Printf("Create and sync with 'github.com/%s/%s' (%s)? [y/N] ", user, repoName, visibility)
+	fmt.Printf("Create and sync with '%s' (%s)? [y/N] ", target, visibility)
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -94,55 +96,42 @@ func runCreateRemote(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// 4. Ensure store exists
+	// 3. Ensure store exists
 	store, err := app.EnsureStore()
 	if err != nil {
 		return err
 	}
 
-	// 4.1 Check if there are commits; if not, create initial commit
-	if err := exec.Command("git", "-C", store, "rev-parse", "--verify", "HEAD").Run(); err != nil {
-		// HEAD not found, create initial commit
-		fmt.Println("No commits found. Creating initial commit...")
-		initCommit := exec.Command("git", "commit", "--allow-empty", "-m", "chore: initial commit")
-		initCommit.Dir = store
-		if out, err := initCommit.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to create initial commit: %v\n%s", err, out)
-		}
-	}
-
-	// 5. Run gh repo create
-	// gh repo create <name> --private --source=. --remote=origin --push
-	ghArgs := []string{
-		"repo", "create", repoName,
-		"--source=.",
-		"--remote=origin",
-		"--push",
+	// 3.1 Check if there are commits; if not, create initial commit
+	created, err := app.EnsureInitialCommit(store)
+	if err != nil {
+		return err
 	}
-	if isPublic {
-		ghArgs = append(ghArgs, "--public")
-	} else {
-		ghArgs = append(ghArgs, "--private")
+	if created {
+		fmt.Println("No commits found. Created initial commit.")
 	}
 
+	// 4. Create the repository
 	fmt.Printf("Creating repository...\n")
-	ghCmd := exec.Command("gh", ghArgs...)
-	ghCmd.Dir = store
-	ghCmd.Stdout = cmd.OutOrStdout()
-	ghCmd.Stderr = cmd.ErrOrStderr()
+	url, err := provider.Create(store, spec, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
 
-	if err := ghCmd.Run(); err != nil {
-		return fmt.Errorf("failed to create repository: %w", err)
+	if url == "" {
+		return fmt.Errorf("repository %s was created but its URL is unknown: add it with 'git -C %s remote add origin <url>' and run 'pea sync'", target, store)
 	}
 
-	// 6. Get the new remote URL (optional check)
-	remoteURLBytes, err := exec.Command("git", "-C", store, "remote", "get-url", "origin").Output()
-	if err != nil {
-		fmt.Printf("Warning: could not retrieve remote URL from git: %v\n", err)
-	} else {
-		// Just print it for verification
-		fmt.Printf("Linked to remote: %s\n", strings.TrimSpace(string(remoteURLBytes)))
+	// 5. Link and push, unless the provider already did
+	if !app.PushesOnCreate(provider) {
+		if err := app.SetGitRemote(store, url); err != nil {
+			return err
+		}
+		if err := app.Sync(store, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+			return err
+		}
 	}
+	fmt.Printf("Linked to remote: %s\n", url)
 
 	fmt.Println("\n✅ Repository created and linked successfully!")
 	return nil
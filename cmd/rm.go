@@ -1,11 +1,10 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"pea/internal/app"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -14,11 +13,23 @@ func addRemoveCommand(root *cobra.Command) {
 	var confirm bool
 	var dryRun bool
 	var undo bool
+	var sel selectorFlags
 
 	cmd := &cobra.Command{
-		Use:               "rm <name>",
-		Short:             "delete an entry",
-		Args:              cobra.ExactArgs(1),
+		Use:   "rm <name|glob>... [--tag tag] [--query text]",
+		Short: "delete entries",
+		Long: "Delete entries by name or glob (quote globs such as 'draft_*'), or select them with\n" +
+			"--tag and --query; all given criteria must match. Each entry's <name>.tests.toml and\n" +
+			"pin go with it, and several entries are removed in a single commit.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if undo {
+				return cobra.ExactArgs(1)(cmd, args)
+			}
+			if len(args) == 0 && !sel.isSet() {
+				return fmt.Errorf("requires a name, glob, --tag or --query")
+			}
+			return nil
+		},
 		ValidArgsFunction: completeNames,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if undo {
@@ -47,42 +58,91 @@ func addRemoveCommand(root *cobra.Command) {
 			if err != nil {
 				return err
 			}
-			name, err := app.NormalizeName(args[0])
+			names, err := selectEntries(store, sel.selector(args))
 			if err != nil {
-				return err
+				return fmt.Errorf("delete failed: %w", err)
 			}
-			path, ext, err := app.ExistingEntryPath(store, name)
-			if err != nil {
-				if os.IsNotExist(err) {
-					return fmt.Errorf("delete failed: not found: %s", name)
+			// files are the entries themselves; paths also holds their tests files.
+			var paths, files []string
+			for _, name := range names {
+				path, ext, err := app.ExistingEntryPath(store, name)
+				if err != nil {
+					return err
 				}
-				return err
+				paths = append(paths, path)
+				files = append(files, name+ext)
+				if tests := app.TestsFile(store, name); app.FileExists(tests) {
+					paths = append(paths, tests)
+				}
+			}
+			unpin, err := app.PlanUnpin(store, names)
+			if err != nil {
+				return fmt.Errorf("delete failed: %w", err)
 			}
 			if dryRun {
-				_, err := fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would delete %s%s\n", name, ext)
-				return err
+				for _, p := range paths {
+					if _, err := fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would delete %s\n", filepath.Base(p)); err != nil {
+						return err
+					}
+				}
+				if unpin != nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would update %s\n", unpin.Describe())
+				}
+				return nil
 			}
 			if confirm {
-				fmt.Fprintf(cmd.OutOrStdout(), "Delete %s%s? [y/N]: ", name, ext)
-				reader := bufio.NewReader(cmd.InOrStdin())
-				ans, _ := reader.ReadString('\n')
-				ans = strings.ToLower(strings.TrimSpace(ans))
-				if ans != "y" && ans != "yes" {
+				question := fmt.Sprintf("Delete %s?", files[0])
+				if len(files) > 1 {
+					for _, f := range files {
+						fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", f)
+					}
+					question = fmt.Sprintf("Delete %d entries?", len(files))
+				}
+				if !askConfirm(cmd, question) {
 					return fmt.Errorf("delete aborted")
 				}
 			}
-			if err := os.Remove(path); err != nil {
-				return fmt.Errorf("delete failed: %w", err)
+			unlock, err := app.LockStore(store)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			// Stage removals and the pins file together; untracked files
+			// leave nothing to stage.
+			var stage []string
+			for _, p := range paths {
+				if err := os.Remove(p); err != nil {
+					return fmt.Errorf("delete failed: %w", err)
+				}
+				if rel := filepath.Base(p); app.IsTracked(store, rel) {
+					stage = append(stage, rel)
+				}
+			}
+			if unpin != nil {
+				if err := app.WriteFileAtomic(unpin.Path, unpin.Content, 0o644); err != nil {
+					return fmt.Errorf("update %s: %w", unpin.Describe(), err)
+				}
+				stage = append(stage, unpin.File)
+			}
+			// git add + commit (best-effort)
+			commitMsg := "chore: remove " + files[0]
+			if len(files) > 1 {
+				commitMsg = fmt.Sprintf("chore: remove %d entries", len(files))
+			}
+			if len(stage) > 0 {
+				app.GitAddAndCommit(store, stage, commitMsg, cmd.ErrOrStderr())
+			}
+			for _, name := range names {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), name); err != nil {
+					return err
+				}
 			}
-			// git rm + commit (best-effort)
-			commitMsg := "chore: remove " + name + ext
-			app.GitRmAndCommit(store, []string{name + ext}, commitMsg, cmd.ErrOrStderr())
-			_, err = fmt.Fprintln(cmd.OutOrStdout(), name)
-			return err
+			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&confirm, "confirm", false, "prompt before deleting")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would happen without deleting")
 	cmd.Flags().BoolVar(&undo, "undo", false, "undo last delete for this entry via git revert")
+	sel.register(cmd)
 	root.AddCommand(cmd)
 }
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"pea/internal/app"
 
@@ -19,9 +21,10 @@ func NewRootCmd() *cobra.Command {
 	cfgPath := filepath.Join(base, "config.toml")
 
 	cmd := &cobra.Command{
-		Use:   "pea",
-		Short: "pea: fast local prompt storage & retrieval",
-		Long:  fmt.Sprintf("pea is a fast, local CLI to store short text under names and retrieve it instantly.\n\nDefaults: store at %s; config at %s; env override: PEA_STORE (highest precedence).", defaultStore, cfgPath),
+		Use:               "pea",
+		Short:             "pea: fast local prompt storage & retrieval",
+		Long:              fmt.Sprintf("pea is a fast, local CLI to store short text under names and retrieve it instantly.\n\n'pea <name>' is shorthand for 'pea get <name>'; aliases from the [aliases] config table expand before dispatch.\n\nDefaults: store at %s; config at %s; env override: PEA_STORE (highest precedence).", defaultStore, cfgPath),
+		ValidArgsFunction: completeRootArgs,
 	}
 
 	cmd.SilenceUsage = true
@@ -42,14 +45,93 @@ func NewRootCmd() *cobra.Command {
 	addCompletionCommand(cmd)
 	addRemoteCommand(cmd)
 	addSyncCommand(cmd)
+	addStatusCommand(cmd)
+	addCommitCommand(cmd)
+	addDepsCommand(cmd)
+	addLintCommand(cmd)
+	addMigrateCommand(cmd)
+	addTopCommand(cmd)
+	addPinCommands(cmd)
+	addRunCommand(cmd)
+	addAskCommand(cmd)
+	addTestCommand(cmd)
+	addTagCommand(cmd)
+	addExportCommand(cmd)
+	addClipboardClearCommand(cmd)
+
+	var names []string
+	for _, c := range cmd.Commands() {
+		names = append(names, c.Name())
+		names = append(names, c.Aliases...)
+	}
+	app.SetCommandNames(names)
 
 	return cmd
 }
 
 func Execute() {
 	root := NewRootCmd()
+	args, err := rewriteArgs(root, os.Args[1:], app.ReadConfig().Aliases)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	root.SetArgs(args)
 	if err := root.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
+
+// exitError makes Execute exit with code rather than 1, for commands whose
+// exit status tells several kinds of failure apart.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// rewriteArgs expands a leading alias and turns 'pea <name> ...' into
+// 'pea get <name> ...' before cobra sees the arguments. Completion requests
+// are rewritten the same way once the first word is complete.
+func rewriteArgs(root *cobra.Command, args []string, aliases map[string]string) ([]string, error) {
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		if len(args) < 3 {
+			return args, nil
+		}
+		rest, err := rewriteArgs(root, args[1:], aliases)
+		if err != nil {
+			return nil, err
+		}
+		return append([]string{args[0]}, rest...), nil
+	}
+
+	isCommand := func(name string) bool { return isSubcommand(root, name) }
+	args, err := app.ExpandAlias(args, aliases, isCommand)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") && !isCommand(args[0]) {
+		return append([]string{"get"}, args...), nil
+	}
+	return args, nil
+}
+
+func isSubcommand(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addRunCommand(root *cobra.Command) {
+	var varPairs []string
+	var runner string
+
+	cmd := &cobra.Command{
+		Use:   "run <name> [--var name=value...] [-- command [args...]]",
+		Short: "render an entry and pipe it into a command",
+		Long: "Render an entry (includes and template variables) and feed it on stdin to a command,\n" +
+			"streaming the command's output back. The command is given after --, or taken from the\n" +
+			"[runners] config table: --runner picks one by name, otherwise the entry's 'runner:' front\n" +
+			"matter, otherwise the default 'runner' from the config. Named runners run via sh -c.",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			command := args[1:]
+			if dash := cmd.ArgsLenAtDash(); dash == -1 && len(command) > 0 {
+				return fmt.Errorf("unexpected arguments %v: put the command after --", command)
+			} else if dash > 1 {
+				return fmt.Errorf("run takes a single entry name before --")
+			}
+
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			vars, err := app.ParseVars(varPairs)
+			if err != nil {
+				return err
+			}
+			b, err := app.RenderEntry(store, name, "", vars)
+			if err != nil {
+				return err
+			}
+
+			var c *exec.Cmd
+			if len(command) > 0 {
+				c = exec.Command(command[0], command[1:]...)
+			} else {
+				file, err := app.ReadEntryFile(store, name)
+				if err != nil {
+					return err
+				}
+				line, err := app.ResolveRunner(file, runner)
+				if err != nil {
+					return err
+				}
+				c = exec.Command("sh", "-c", line)
+			}
+			c.Stdin = bytes.NewReader(b)
+			c.Stdout = cmd.OutOrStdout()
+			c.Stderr = cmd.ErrOrStderr()
+
+			recordUsage(store, name, app.UsageRun)
+			if err := c.Run(); err != nil {
+				return fmt.Errorf("runner failed: %w", err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&varPairs, "var", nil, "set a template variable (name=value, repeatable)")
+	cmd.Flags().StringVar(&runner, "runner", "", "named runner from the [runners] config table")
+	_ = cmd.RegisterFlagCompletionFunc("runner", completeRunners)
+	root.AddCommand(cmd)
+}
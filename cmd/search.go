@@ -60,5 +60,6 @@ func addSearchCommand(root *cobra.Command) {
 		},
 	}
 	cmd.Flags().StringArrayVar(&tags, "tag", nil, "filter by tag (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags)
 	root.AddCommand(cmd)
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+// selectorFlags are the --tag and --query flags shared by bulk commands.
+type selectorFlags struct {
+	tags  []string
+	query string
+}
+
+func (f *selectorFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.tags, "tag", nil, "select entries with this tag (repeatable)")
+	cmd.Flags().StringVar(&f.query, "query", "", "select entries whose name or content contains this text")
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags)
+}
+
+// selector combines the flags with name or glob patterns.
+func (f *selectorFlags) selector(patterns []string) app.Selector {
+	return app.Selector{Patterns: patterns, Tags: f.tags, Query: f.query}
+}
+
+// isSet reports whether --tag or --query was given.
+func (f *selectorFlags) isSet() bool {
+	return len(f.tags) > 0 || f.query != ""
+}
+
+// selectEntries resolves a selector, failing when nothing matches.
+func selectEntries(store string, s app.Selector) ([]string, error) {
+	names, err := app.SelectEntries(store, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no entries match")
+	}
+	return names, nil
+}
+
+// askConfirm prints question and reports whether the user answered yes.
+func askConfirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s [y/N]: ", question)
+	reader := bufio.NewReader(cmd.InOrStdin())
+	ans, _ := reader.ReadString('\n')
+	ans = strings.ToLower(strings.TrimSpace(ans))
+	return ans == "y" || ans == "yes"
+}
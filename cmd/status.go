@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addStatusCommand(root *cobra.Command) {
+	var fetch bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "show uncommitted entries and sync status of the store",
+		Long: "Show entries and other store files, such as tests files, that are modified, untracked\n" +
+			"or deleted but not committed, the configured push mode, and commits not yet pushed to\n" +
+			"or pulled from origin.\n" +
+			"Use --fetch to refresh what origin has before comparing.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+
+			if !app.GitEnabled(store) {
+				_, err := fmt.Fprintln(out, "git: disabled")
+				return err
+			}
+
+			if fetch {
+				if err := app.Fetch(store); err != nil {
+					return err
+				}
+			}
+
+			st, err := app.GetStoreStatus(store)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range st.Changes {
+				fmt.Fprintf(out, "%-10s %s\n", c.State+":", c.Name)
+			}
+			for _, c := range st.Other {
+				fmt.Fprintf(out, "%-10s %s\n", c.State+":", c.File)
+			}
+			if len(st.Changes) == 0 && len(st.Other) == 0 {
+				fmt.Fprintln(out, "working tree clean")
+			} else {
+				fmt.Fprintln(out, "run 'pea commit' to commit these changes")
+			}
+
+			mode, batch := app.GetPushConfig()
+			if mode == app.PushBatched {
+				fmt.Fprintf(out, "push: %s (every %d commits)\n", mode, batch)
+			} else {
+				fmt.Fprintf(out, "push: %s\n", mode)
+			}
+
+			if !app.HasRemote(store) {
+				_, err := fmt.Fprintln(out, "remote: none")
+				return err
+			}
+			fmt.Fprintf(out, "remote: %s\n", app.RemoteURL(store))
+
+			if len(st.Unpushed) == 0 && len(st.Unpulled) == 0 {
+				_, err := fmt.Fprintln(out, "up to date")
+				return err
+			}
+			if len(st.Unpushed) > 0 {
+				fmt.Fprintf(out, "unpushed commits: %d\n", len(st.Unpushed))
+				for _, c := range st.Unpushed {
+					fmt.Fprintf(out, "  %s\n", c)
+				}
+			}
+			if len(st.Unpulled) > 0 {
+				fmt.Fprintf(out, "unpulled commits: %d\n", len(st.Unpulled))
+				for _, c := range st.Unpulled {
+					fmt.Fprintf(out, "  %s\n", c)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&fetch, "fetch", false, "fetch from origin before comparing")
+	root.AddCommand(cmd)
+}
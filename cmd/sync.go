@@ -1,24 +1,47 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"pea/internal/app"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 func addSyncCommand(root *cobra.Command) {
+	var watch bool
+	var interval time.Duration
+
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Manually sync with remote git repository",
-		Long:  "Perform a manual git pull --rebase and git push to synchronize with the configured remote.",
-		Args:  cobra.NoArgs,
+		Long: "Perform a manual git pull --rebase and git push to synchronize with the configured remote.\n" +
+			"This also flushes commits queued by the batched and manual push modes.\n\n" +
+			"With --watch, keep running: commit files changed outside pea as they appear and\n" +
+			"pull/push on every interval until interrupted.",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			store, err := app.EnsureStore()
 			if err != nil {
 				return err
 			}
 
+			if watch {
+				if !app.GitEnabled(store) {
+					return fmt.Errorf("sync --watch requires git to be enabled")
+				}
+				if interval <= 0 {
+					return fmt.Errorf("invalid --interval: must be positive")
+				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return runSyncWatch(ctx, cmd, store, interval)
+			}
+
 			fmt.Println("Syncing with remote...")
 			if err := app.Sync(store, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
 				return err
@@ -27,5 +50,63 @@ func addSyncCommand(root *cobra.Command) {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&watch, "watch", false, "keep running and sync in the background")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "time between pulls/pushes in watch mode")
 	root.AddCommand(cmd)
 }
+
+// syncWatchSettle is how long files must stay unchanged before the watcher
+// commits them, so editors can finish writing.
+const syncWatchSettle = 500 * time.Millisecond
+
+// runSyncWatch watches the store for external edits, committing them once a
+// change has settled, and syncs with the remote on every interval.
+func runSyncWatch(ctx context.Context, cmd *cobra.Command, store string, interval time.Duration) error {
+	out := cmd.OutOrStdout()
+	errOut := cmd.ErrOrStderr()
+
+	syncTicker := time.NewTicker(interval)
+	defer syncTicker.Stop()
+
+	changed := make(chan struct{}, 1)
+	changed <- struct{}{} // pick up anything left uncommitted before we started
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- app.WatchStore(ctx, store, syncWatchSettle, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	fmt.Fprintf(out, "watching %s (sync every %s, Ctrl-C to stop)\n", store, interval)
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out, "stopped")
+			return nil
+		case err := <-watchErr:
+			if err != nil {
+				return fmt.Errorf("watch %s: %w", store, err)
+			}
+		case <-changed:
+			committed, err := app.CommitStoreChanges(store, "chore: sync external changes")
+			if err != nil {
+				fmt.Fprintf(errOut, "warning: commit failed: %v\n", err)
+				continue
+			}
+			if committed {
+				fmt.Fprintf(out, "%s committed external changes\n", time.Now().Format(time.TimeOnly))
+			}
+		case <-syncTicker.C:
+			if err := app.Sync(store, nil, errOut); err != nil {
+				fmt.Fprintf(errOut, "warning: sync failed: %v\n", err)
+				continue
+			}
+			if app.HasRemote(store) {
+				fmt.Fprintf(out, "%s synced\n", time.Now().Format(time.TimeOnly))
+			}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addTagCommand(root *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "manage entry tags",
+		Long: "List, add, remove and rename tags in the front matter of entries, keeping each entry's\n" +
+			"inline or list style. For add and rm the first argument is an entry name or glob;\n" +
+			"--tag and --query narrow the selection. With either of them the name or glob may be left\n" +
+			"out by starting the tags with -- ('pea tag add --tag old -- new'). Every change is made\n" +
+			"in a single commit.",
+	}
+	cmd.AddCommand(newTagEditCommand("add", "add tags to entries", app.AddTags))
+	cmd.AddCommand(newTagEditCommand("rm", "remove tags from entries", app.RemoveTags))
+	cmd.AddCommand(newTagListCommand())
+	cmd.AddCommand(newTagRenameCommand())
+	root.AddCommand(cmd)
+}
+
+func newTagListCommand() *cobra.Command {
+	var sortBy string
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "list tags with the number of entries using them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			counts, err := app.AllTags(store)
+			if err != nil {
+				return err
+			}
+			tags := make([]string, 0, len(counts))
+			for t := range counts {
+				tags = append(tags, t)
+			}
+			switch sortBy {
+			case "count":
+				sort.Slice(tags, func(i, j int) bool {
+					if counts[tags[i]] != counts[tags[j]] {
+						return counts[tags[i]] > counts[tags[j]]
+					}
+					return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+				})
+			case "name":
+				sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+			default:
+				return fmt.Errorf("invalid --sort %q: use count or name", sortBy)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, t := range tags {
+				fmt.Fprintf(tw, "%d\t%s\n", counts[t], t)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&sortBy, "sort", "count", "order by count or name")
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"count", "name"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func newTagRenameCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "rename a tag in every entry",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeTags(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			tags, err := app.ParseTagArgs(args[:1])
+			if err != nil {
+				return err
+			}
+			oldTag := tags[0]
+			if tags, err = app.ParseTagArgs(args[1:]); err != nil {
+				return err
+			}
+			newTag := tags[0]
+			if oldTag == newTag {
+				return fmt.Errorf("tag %q is unchanged", oldTag)
+			}
+			names, err := app.SelectEntries(store, app.Selector{Tags: []string{oldTag}})
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("no entries tagged %q", oldTag)
+			}
+			rename := func(current []string) []string { return app.RenameTag(current, oldTag, newTag) }
+
+			if dryRun {
+				updates, err := app.PlanTagEdit(store, names, rename)
+				if err != nil {
+					return err
+				}
+				for _, u := range updates {
+					fmt.Fprintf(cmd.OutOrStdout(), "dry-run: would update %s (tags: %s)\n", u.File, strings.Join(u.Tags, ", "))
+				}
+				return nil
+			}
+
+			unlock, err := app.LockStore(store)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			updates, err := app.PlanTagEdit(store, names, rename)
+			if err != nil {
+				return err
+			}
+			var files []string
+			for _, u := range updates {
+				if err := app.WriteFileAtomic(filepath.Join(store, u.File), u.Content, 0o644); err != nil {
+					return fmt.Errorf("update %s: %w", u.File, err)
+				}
+				files = append(files, u.File)
+			}
+			if len(files) > 0 {
+				app.GitAddAndCommit(store, files, fmt.Sprintf("chore: rename tag %s to %s in %d entries", oldTag, newTag, len(files)), cmd.ErrOrStderr())
+			}
+			for _, u := range updates {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), u.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would change without writing")
+	return cmd
+}
+
+// newTagEditCommand builds 'tag add' and 'tag rm', which apply edit to the
+// tags of the selected entries.
+func newTagEditCommand(use, short string, edit func(tags, changed []string) []string) *cobra.Command {
+	var confirm bool
+	var dryRun bool
+	var sel selectorFlags
+
+	cmd := &cobra.Command{
+		Use:   use + " <name|glob> <tags...> | " + use + " --tag|--query <selector> -- <tags...>",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeNames(cmd, args, toComplete)
+			}
+			return completeTags(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			// Without --, the first argument is the name or glob. With it,
+			// the arguments before -- select entries and the rest are tags.
+			patterns, tagArgs := args[:1], args[1:]
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				patterns, tagArgs = args[:dash], args[dash:]
+			}
+			if len(patterns) == 0 && !sel.isSet() {
+				return fmt.Errorf("tag %s needs a name or glob unless --tag or --query is given", use)
+			}
+			if len(tagArgs) == 0 {
+				return fmt.Errorf("tag %s needs a name or glob and at least one tag, or --tag/--query, -- and a tag", use)
+			}
+			tags, err := app.ParseTagArgs(tagArgs)
+			if err != nil {
+				return err
+			}
+			names, err := selectEntries(store, sel.selector(patterns))
+			if err != nil {
+				return err
+			}
+			apply := func(current []string) []string { return edit(current, tags) }
+
+			if dryRun || confirm {
+				updates, err := app.PlanTagEdit(store, names, apply)
+				if err != nil {
+					return err
+				}
+				prefix := " "
+				if dryRun {
+					prefix = "dry-run: would update"
+				}
+				for _, u := range updates {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s (tags: %s)\n", prefix, u.File, orDash(strings.Join(u.Tags, ", ")))
+				}
+				if dryRun || len(updates) == 0 {
+					return nil
+				}
+				if !askConfirm(cmd, fmt.Sprintf("Update %d entries?", len(updates))) {
+					return fmt.Errorf("tag %s aborted", use)
+				}
+			}
+
+			unlock, err := app.LockStore(store)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			updates, err := app.PlanTagEdit(store, names, apply)
+			if err != nil {
+				return err
+			}
+			if len(updates) == 0 {
+				cmd.PrintErrln("nothing to change")
+				return nil
+			}
+			var files []string
+			for _, u := range updates {
+				if err := app.WriteFileAtomic(filepath.Join(store, u.File), u.Content, 0o644); err != nil {
+					return fmt.Errorf("update %s: %w", u.File, err)
+				}
+				files = append(files, u.File)
+			}
+			app.GitAddAndCommit(store, files, tagCommitMessage(use, updates, tags), cmd.ErrOrStderr())
+			for _, u := range updates {
+				if _, err := fmt.Fprintln(cmd.OutOrStdout(), u.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&confirm, "confirm", false, "prompt before changing entries")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would change without writing")
+	sel.register(cmd)
+	return cmd
+}
+
+func tagCommitMessage(use string, updates []app.TagUpdate, tags []string) string {
+	target := updates[0].File
+	if len(updates) > 1 {
+		target = fmt.Sprintf("%d entries", len(updates))
+	}
+	if use == "add" {
+		return fmt.Sprintf("chore: tag %s with %s", target, strings.Join(tags, ", "))
+	}
+	return fmt.Sprintf("chore: untag %s from %s", strings.Join(tags, ", "), target)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+// testOutputLines caps how much runner output a failed case prints.
+const testOutputLines = 20
+
+func addTestCommand(root *cobra.Command) {
+	var runner string
+
+	cmd := &cobra.Command{
+		Use:   "test [names...] [--runner name] [-- command [args...]]",
+		Short: "run an entry's test cases through a runner",
+		Long: "Run the test cases kept next to an entry in <name>.tests.toml. Each [[case]] renders the\n" +
+			"entry with its vars, feeds it to a runner and checks the output against contains,\n" +
+			"not_contains, regex and json_schema assertions. The runner is the command after --,\n" +
+			"else --runner, else the tests file's 'runner', else the entry's or config's runner.\n" +
+			"Without names, every entry with a tests file is tested. Exits non-zero on any failure.",
+		ValidArgsFunction: completeNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := args
+			var command []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				names, command = args[:dash], args[dash:]
+				if len(command) == 0 {
+					return fmt.Errorf("missing command after --")
+				}
+			}
+
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				if names, err = app.EntriesWithTests(store); err != nil {
+					return err
+				}
+				if len(names) == 0 {
+					return fmt.Errorf("no tests found: add a <name>.tests.toml next to an entry")
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			passed, failed := 0, 0
+			for _, raw := range names {
+				name, err := app.NormalizeName(raw)
+				if err != nil {
+					return err
+				}
+				suite, err := app.LoadTests(store, name)
+				if err != nil {
+					return err
+				}
+				line := ""
+				if len(command) == 0 {
+					file, err := app.ReadEntryFile(store, name)
+					if err != nil {
+						return err
+					}
+					flag := runner
+					if flag == "" {
+						flag = suite.Runner
+					}
+					if line, err = app.ResolveRunner(file, flag); err != nil {
+						return err
+					}
+				}
+				for _, tc := range suite.Cases {
+					failures, output, err := runTestCase(store, name, tc, command, line)
+					if err != nil {
+						failures = append(failures, err.Error())
+					}
+					if len(failures) == 0 {
+						passed++
+						fmt.Fprintf(out, "PASS %s: %s\n", name, tc.Name)
+						continue
+					}
+					failed++
+					fmt.Fprintf(out, "FAIL %s: %s\n", name, tc.Name)
+					for _, f := range failures {
+						fmt.Fprintf(out, "  - %s\n", f)
+					}
+					printTestOutput(out, output)
+				}
+			}
+
+			fmt.Fprintf(out, "%d passed, %d failed\n", passed, failed)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d test cases failed", failed, passed+failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&runner, "runner", "", "named runner from the [runners] config table")
+	_ = cmd.RegisterFlagCompletionFunc("runner", completeRunners)
+	root.AddCommand(cmd)
+}
+
+// runTestCase renders the entry for tc, pipes it into the runner and checks
+// the assertions against what the runner wrote to stdout.
+func runTestCase(store, name string, tc app.TestCase, command []string, line string) ([]string, string, error) {
+	b, err := app.RenderEntry(store, name, "", tc.Vars)
+	if err != nil {
+		return nil, "", err
+	}
+	var c *exec.Cmd
+	if len(command) > 0 {
+		c = exec.Command(command[0], command[1:]...)
+	} else {
+		c = exec.Command("sh", "-c", line)
+	}
+	var stdout, stderr bytes.Buffer
+	c.Stdin = bytes.NewReader(b)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, stdout.String(), fmt.Errorf("runner failed: %w: %s", err, msg)
+		}
+		return nil, stdout.String(), fmt.Errorf("runner failed: %w", err)
+	}
+	failures, err := tc.CheckOutput(stdout.String())
+	return failures, stdout.String(), err
+}
+
+func printTestOutput(w io.Writer, output string) {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return
+	}
+	lines := strings.Split(output, "\n")
+	fmt.Fprintln(w, "  output:")
+	for i, l := range lines {
+		if i == testOutputLines {
+			fmt.Fprintf(w, "    ... (%d more lines)\n", len(lines)-i)
+			break
+		}
+		fmt.Fprintf(w, "    %s\n", l)
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"pea/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+func addTopCommand(root *cobra.Command) {
+	var limit int
+	var sortBy string
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "show the most frequently retrieved entries",
+		Long: "Show entries ordered by how often they were retrieved with get, cp, run or ask, with the\n" +
+			"time of the last retrieval. Usage is recorded locally in ~/.pea/usage.tsv and never\n" +
+			"committed; set 'track_usage = false' in the config to turn recording off.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			store, err := app.EnsureStore()
+			if err != nil {
+				return err
+			}
+			usage, err := app.LoadUsage(store)
+			if err != nil {
+				return err
+			}
+			var names []string
+			entries, err := app.ListEntries(store)
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if usage[e].Count > 0 {
+					names = append(names, e)
+				}
+			}
+			if err := app.SortEntries(names, sortBy, usage); err != nil {
+				return err
+			}
+			if limit > 0 && len(names) > limit {
+				names = names[:limit]
+			}
+
+			out := cmd.OutOrStdout()
+			if len(names) == 0 {
+				_, err := fmt.Fprintln(out, "no usage recorded yet")
+				return err
+			}
+			tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, n := range names {
+				fmt.Fprintf(tw, "%d\t%s\t%s\n", usage[n].Count, usage[n].Last.Format("2006-01-02 15:04"), n)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of entries to show (0 for all)")
+	cmd.Flags().StringVar(&sortBy, "sort", app.SortFrequent, "order by frequent or recent")
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{app.SortFrequent, app.SortRecent}, cobra.ShellCompDirectiveNoFileComp))
+	root.AddCommand(cmd)
+}
@@ -59,3 +59,12 @@ func isInputFromPipe() bool {
 	}
 	return (fi.Mode() & os.ModeCharDevice) == 0
 }
+
+// recordUsage logs a retrieval for 'pea top' and usage-ordered listings.
+// Failures are ignored: usage data is a convenience, never worth failing a
+// retrieval over.
+func recordUsage(store, name, kind string) {
+	if n, err := app.NormalizeName(name); err == nil {
+		_ = app.RecordUsage(store, n, kind)
+	}
+}
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"os"
 	"os/exec"
 	"strings"
 	"testing"
@@ -25,3 +26,26 @@ func TestAddRejectsReservedNames(t *testing.T) {
 		}
 	}
 }
+
+func TestAddRejectsCommandAndAliasNames(t *testing.T) {
+	home, _ := peaHome(t, "[aliases]\nfj = \"get foo --format json\"\n")
+
+	for _, name := range []string{"status", "sync", "tag", "test", "fj"} {
+		c := exec.Command(buildBinary(t), "add", name)
+		c.Env = append(os.Environ(), "HOME="+home)
+		c.Stdin = strings.NewReader("content\n")
+		out, err := c.CombinedOutput()
+		if err == nil {
+			t.Errorf("add %s should have failed:\n%s", name, out)
+			continue
+		}
+		if !strings.Contains(string(out), "invalid name") {
+			t.Errorf("add %s error message missing 'invalid name': %s", name, out)
+		}
+	}
+
+	runPea(t, home, "keep\n", "add", "notes")
+	if out := peaFails(t, home, "mv", "notes", "status"); !strings.Contains(out, "reserved command") {
+		t.Fatalf("mv onto a command name should be rejected: %s", out)
+	}
+}
@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestBareNameRetrieves(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "bare content\n", "add", "bare_notes")
+
+	if out := runPea(t, home, "", "bare_notes"); out != "bare content\n" {
+		t.Fatalf("unexpected bare-name output: %q", out)
+	}
+
+	c := exec.Command(buildBinary(t), "missing_entry")
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "not found: missing_entry") {
+		t.Fatalf("expected not found error, got err=%v: %s", err, out)
+	}
+}
+
+func TestAliasExpandsWithArguments(t *testing.T) {
+	home, _ := peaHome(t, "[aliases]\nr = \"get --raw\"\nrr = \"r\"\nls = \"rm\"\n")
+	runPea(t, home, "{{> alias_missing}}\n", "add", "alias_entry")
+
+	if out := runPea(t, home, "", "r", "alias_entry"); out != "{{> alias_missing}}\n" {
+		t.Fatalf("alias should expand to get --raw: %q", out)
+	}
+	if out := runPea(t, home, "", "rr", "alias_entry"); out != "{{> alias_missing}}\n" {
+		t.Fatalf("nested alias should expand: %q", out)
+	}
+	if out := runPea(t, home, "", "ls"); !strings.Contains(out, "alias_entry") {
+		t.Fatalf("aliases must not shadow built-in commands: %q", out)
+	}
+}
+
+func TestAliasCycleFails(t *testing.T) {
+	home, _ := peaHome(t, "[aliases]\na = \"b\"\nb = \"a --raw\"\n")
+
+	c := exec.Command(buildBinary(t), "a")
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "alias cycle: a -> b -> a") {
+		t.Fatalf("expected alias cycle error, got err=%v: %s", err, out)
+	}
+}
+
+func TestRootCompletionOffersNamesAndAliases(t *testing.T) {
+	home, _ := peaHome(t, "[aliases]\nshortcut = \"get --raw\"\n")
+	runPea(t, home, "x\n", "add", "completable")
+
+	out := runPea(t, home, "", "__complete", "")
+	for _, want := range []string{"completable", "shortcut", "get"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("root completion missing %q:\n%s", want, out)
+		}
+	}
+
+	out = runPea(t, home, "", "__complete", "completable", "--r")
+	if !strings.Contains(out, "--raw") {
+		t.Fatalf("bare-name completion should offer get flags:\n%s", out)
+	}
+}
@@ -0,0 +1,140 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type chatRequest struct {
+	Model       string   `json:"model"`
+	Temperature *float64 `json:"temperature"`
+	Stream      bool     `json:"stream"`
+	Messages    []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+// fakeChatAPI serves /v1/chat/completions, streaming the reply in chunks
+// like llama.cpp or OpenAI do, or as one JSON body when stream is false.
+func fakeChatAPI(t *testing.T, stream bool, reply ...string) (*httptest.Server, *chatRequest, *string) {
+	t.Helper()
+	var got chatRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if got.Model == "missing" {
+			http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+			return
+		}
+		if !stream {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": strings.Join(reply, "")}}},
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range reply {
+			b, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]string{"content": part}}}})
+			fmt.Fprintf(w, "data: %s\n\n", b)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got, &auth
+}
+
+// llmConfig points the [llm] config table at a fake chat endpoint.
+func llmConfig(endpoint string) string {
+	return "[llm]\nendpoint = \"" + endpoint + "/v1\"\napi_key = \"sk-test\"\nmodel = \"default-model\"\n"
+}
+
+func TestAskStreamsReplyAndSaves(t *testing.T) {
+	srv, got, auth := fakeChatAPI(t, true, "Hello", ", ", "world")
+	home, _ := peaHome(t, llmConfig(srv.URL))
+	runPea(t, home, "---\nmodel: local-llama\ntemperature: 0.2\nsystem: \"You are terse.\"\nvars: [topic]\n---\nExplain {{topic}}.\n", "add", "ask_explain")
+
+	out := runPea(t, home, "", "ask", "ask_explain", "--var", "topic=channels", "--save", "ask_answer")
+	if !strings.HasPrefix(out, "Hello, world\n") {
+		t.Fatalf("unexpected ask output: %q", out)
+	}
+
+	if got.Model != "local-llama" || got.Temperature == nil || *got.Temperature != 0.2 || !got.Stream {
+		t.Fatalf("front matter options not sent: %+v", got)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[0].Content != "You are terse." ||
+		got.Messages[1].Role != "user" || got.Messages[1].Content != "Explain channels." {
+		t.Fatalf("unexpected messages: %+v", got.Messages)
+	}
+	if *auth != "Bearer sk-test" {
+		t.Fatalf("unexpected Authorization header: %q", *auth)
+	}
+
+	b, err := os.ReadFile(filepath.Join(home, ".pea", "prompts", "ask_answer.md"))
+	if err != nil {
+		t.Fatalf("reply was not saved: %v", err)
+	}
+	if string(b) != "Hello, world\n" {
+		t.Fatalf("unexpected saved reply: %q", b)
+	}
+}
+
+func TestAskHandlesNonStreamingServers(t *testing.T) {
+	srv, got, _ := fakeChatAPI(t, false, "whole reply\n")
+	home, _ := peaHome(t, llmConfig(srv.URL))
+	runPea(t, home, "plain prompt\n", "add", "ask_plain")
+
+	if out := runPea(t, home, "", "ask", "ask_plain"); out != "whole reply\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+	if got.Model != "default-model" || len(got.Messages) != 1 {
+		t.Fatalf("config model and a single user message expected: %+v", got)
+	}
+}
+
+func TestAskReportsServerErrors(t *testing.T) {
+	srv, _, _ := fakeChatAPI(t, true, "unused")
+	home, _ := peaHome(t, llmConfig(srv.URL))
+	runPea(t, home, "prompt\n", "add", "ask_error")
+
+	out := peaFails(t, home, "ask", "ask_error", "--model", "missing")
+	if !strings.Contains(out, "404 Not Found") || !strings.Contains(out, "model not found") {
+		t.Fatalf("expected server error to be reported:\n%s", out)
+	}
+}
+
+func TestAskSaveRefusesToOverwrite(t *testing.T) {
+	srv, _, _ := fakeChatAPI(t, true, "new reply")
+	home, _ := peaHome(t, llmConfig(srv.URL))
+	runPea(t, home, "prompt\n", "add", "ask_prompt")
+	runPea(t, home, "keep me\n", "add", "ask_existing")
+	saved := filepath.Join(home, ".pea", "prompts", "ask_existing.md")
+
+	if out := peaFails(t, home, "ask", "ask_prompt", "--save", "ask_existing"); !strings.Contains(out, "entry ask_existing already exists (use --force to overwrite)") {
+		t.Fatalf("expected an overwrite error:\n%s", out)
+	}
+	if b, _ := os.ReadFile(saved); string(b) != "keep me\n" {
+		t.Fatalf("existing entry should be untouched: %q", b)
+	}
+
+	runPea(t, home, "", "ask", "ask_prompt", "--save", "ask_existing", "--force")
+	if b, _ := os.ReadFile(saved); string(b) != "new reply\n" {
+		t.Fatalf("--force should overwrite: %q", b)
+	}
+}
@@ -0,0 +1,205 @@
+package e2e
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// seedBulkEntries adds the entries the selector tests work on.
+func seedBulkEntries(t *testing.T, home string) {
+	t.Helper()
+	runPea(t, home, "---\ntags: [old, draft]\n---\none\n", "add", "draft_one")
+	runPea(t, home, "---\ntags:\n  - draft\n---\ntwo about channels\n", "add", "draft_two")
+	runPea(t, home, "---\ntags: old\n---\nlegacy\n", "add", "legacy")
+	runPea(t, home, "keep me\n", "add", "keeper")
+}
+
+func commitCount(t *testing.T, store string) int {
+	t.Helper()
+	return strings.Count(gitOut(t, store, "log", "--oneline"), "\n")
+}
+
+func TestBulkRemoveBySelectors(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedBulkEntries(t, home)
+
+	if out := runPea(t, home, "", "rm", "--tag", "old", "--dry-run"); out != "dry-run: would delete draft_one.md\ndry-run: would delete legacy.md\n" {
+		t.Fatalf("unexpected dry-run: %q", out)
+	}
+	if out := runPea(t, home, "", "rm", "draft_*", "--tag", "old"); out != "draft_one\n" {
+		t.Fatalf("selectors should intersect: %q", out)
+	}
+
+	before := commitCount(t, store)
+	if out := peaFails(t, home, "rm", "draft_*", "legacy", "--confirm"); !strings.Contains(out, "Delete 2 entries? [y/N]") {
+		t.Fatalf("expected bulk prompt: %q", out)
+	}
+	if out := runPea(t, home, "", "rm", "draft_*", "legacy"); out != "draft_two\nlegacy\n" {
+		t.Fatalf("unexpected removal: %q", out)
+	}
+	if n := commitCount(t, store); n != before+1 {
+		t.Fatalf("expected one commit for the bulk delete, got %d", n-before)
+	}
+	if subject := gitOut(t, store, "log", "-n1", "--format=%s"); subject != "chore: remove 2 entries\n" {
+		t.Fatalf("unexpected commit: %q", subject)
+	}
+	if out := runPea(t, home, "", "ls"); out != "keeper\n" {
+		t.Fatalf("unexpected remaining entries: %q", out)
+	}
+	if out := peaFails(t, home, "rm", "nothing_*"); !strings.Contains(out, "no entries match") {
+		t.Fatalf("expected empty selection error:\n%s", out)
+	}
+}
+
+func TestBulkRemoveDropsPinsAndTests(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedBulkEntries(t, home)
+	runPea(t, home, "", "pin", "draft_two")
+	runPea(t, home, "", "pin", "keeper")
+	writeStoreFile(t, store, "draft_two.tests.toml", "[[case]]\nname = \"t\"\n")
+	gitOut(t, store, "add", "draft_two.tests.toml")
+	gitOut(t, store, "commit", "-m", "add tests")
+
+	want := "dry-run: would delete draft_one.md\ndry-run: would delete draft_two.md\ndry-run: would delete draft_two.tests.toml\ndry-run: would update .pins (1 pin)\n"
+	if out := runPea(t, home, "", "rm", "draft_*", "--dry-run"); out != want {
+		t.Fatalf("unexpected dry-run: %q", out)
+	}
+	before := commitCount(t, store)
+	runPea(t, home, "", "rm", "draft_*")
+	if n := commitCount(t, store); n != before+1 {
+		t.Fatalf("expected one commit, got %d", n-before)
+	}
+	if b, _ := os.ReadFile(filepath.Join(store, ".pins")); string(b) != "keeper\n" {
+		t.Fatalf("removed entry should be unpinned: %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(store, "draft_two.tests.toml")); !os.IsNotExist(err) {
+		t.Fatalf("tests file should be removed: %v", err)
+	}
+	if status := gitOut(t, store, "status", "--porcelain"); status != "" {
+		t.Fatalf("removal should be fully committed:\n%s", status)
+	}
+}
+
+func TestBulkMoveIntoFolder(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedBulkEntries(t, home)
+	runPea(t, home, "{{> draft_one}}\n", "add", "uses_draft")
+
+	before := commitCount(t, store)
+	if out := runPea(t, home, "", "mv", "draft_*", "archive/"); !strings.HasSuffix(out, "\narchive_draft_one\narchive_draft_two\n") {
+		t.Fatalf("unexpected move: %q", out)
+	}
+	if n := commitCount(t, store); n != before+1 {
+		t.Fatalf("expected one commit for the bulk move, got %d", n-before)
+	}
+	if b, _ := os.ReadFile(filepath.Join(store, "uses_draft.md")); string(b) != "{{> archive_draft_one}}\n" {
+		t.Fatalf("references should follow the move: %q", b)
+	}
+	if out := runPea(t, home, "", "mv", "--tag", "old", "archive/"); out != "archive_legacy\n" {
+		t.Fatalf("entries already in the folder should be skipped: %q", out)
+	}
+	if out := peaFails(t, home, "mv", "archive_*", "other"); !strings.Contains(out, "needs a folder destination such as other/") {
+		t.Fatalf("expected folder error:\n%s", out)
+	}
+	if out := peaFails(t, home, "mv", "keeper", "archive_legacy"); !strings.Contains(out, "archive_legacy already exists") {
+		t.Fatalf("expected collision error:\n%s", out)
+	}
+}
+
+func TestBulkTagAddAndRemove(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedBulkEntries(t, home)
+
+	before := commitCount(t, store)
+	if out := runPea(t, home, "", "tag", "add", "*", "reviewed", "--tag", "draft"); out != "draft_one\ndraft_two\n" {
+		t.Fatalf("unexpected tag add: %q", out)
+	}
+	if n := commitCount(t, store); n != before+1 {
+		t.Fatalf("expected one commit, got %d", n-before)
+	}
+	runPea(t, home, "", "tag", "add", "keeper", "misc")
+	runPea(t, home, "", "tag", "rm", "*", "old")
+
+	want := map[string]string{
+		"draft_one.md": "---\ntags: [draft, reviewed]\n---\none\n",
+		"draft_two.md": "---\ntags:\n  - draft\n  - reviewed\n---\ntwo about channels\n",
+		"legacy.md":    "legacy\n",
+		"keeper.md":    "---\ntags: [misc]\n---\nkeep me\n",
+	}
+	for file, content := range want {
+		if b, _ := os.ReadFile(filepath.Join(store, file)); string(b) != content {
+			t.Fatalf("%s: got %q, want %q", file, b, content)
+		}
+	}
+	if out := runPea(t, home, "", "tag", "add", "draft_two", "misc", "--dry-run"); out != "dry-run: would update draft_two.md (tags: draft, reviewed, misc)\n" {
+		t.Fatalf("unexpected dry-run: %q", out)
+	}
+	if out := peaFails(t, home, "tag", "add", "keeper", "a,b"); !strings.Contains(out, "invalid tag") {
+		t.Fatalf("expected invalid tag error:\n%s", out)
+	}
+}
+
+func TestExportSelectedEntries(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedBulkEntries(t, home)
+	archive := filepath.Join(t.TempDir(), "drafts.tar.gz")
+
+	if out := peaFails(t, home, "export", "--query", "channels", "legacy", "-o", archive); !strings.Contains(out, "no entries match") {
+		t.Fatalf("legacy does not mention channels:\n%s", out)
+	}
+	if out := runPea(t, home, "", "export", "--tag", "draft", "-o", archive, "--dry-run"); out != "dry-run: would export draft_one\ndry-run: would export draft_two\n" {
+		t.Fatalf("unexpected dry-run: %q", out)
+	}
+	if _, err := os.Stat(archive); err == nil {
+		t.Fatalf("no archive should be written")
+	}
+
+	runPea(t, home, "", "export", "--tag", "draft", "-o", archive)
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var files []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		files = append(files, hdr.Name)
+	}
+	sort.Strings(files)
+	if strings.Join(files, ",") != "draft_one.md,draft_two.md" {
+		t.Fatalf("unexpected archive contents: %v", files)
+	}
+
+	// An entry that can't be read fails the export without leaving a
+	// partial archive or touching an existing one.
+	before, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(t.TempDir(), "missing"), filepath.Join(store, "zz_broken.md")); err != nil {
+		t.Fatal(err)
+	}
+	partial := filepath.Join(t.TempDir(), "all.tar.gz")
+	peaFails(t, home, "export", "-o", partial)
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Fatalf("a failed export must not leave an archive: %v", err)
+	}
+	peaFails(t, home, "export", "-o", archive)
+	if after, _ := os.ReadFile(archive); !bytes.Equal(after, before) {
+		t.Fatalf("a failed export must not overwrite the existing archive")
+	}
+}
@@ -0,0 +1,80 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setFakeClipboard(t *testing.T, s string) {
+	t.Helper()
+	if err := os.WriteFile(os.Getenv("PEA_FAKE_CLIP_FILE"), []byte(s), 0o644); err != nil {
+		t.Fatalf("write fake clipboard: %v", err)
+	}
+}
+
+func TestAddFromClipboard(t *testing.T) {
+	bin := buildBinary(t)
+	setFakeClipboard(t, "prompt copied from chat\n")
+
+	if out, err := exec.Command(bin, "add", "from_clip", "--from-clipboard").CombinedOutput(); err != nil {
+		t.Fatalf("add --from-clipboard failed: %v\n%s", err, out)
+	}
+
+	b, err := os.ReadFile(filepath.Join(storePath(), "from_clip.md"))
+	if err != nil {
+		t.Fatalf("entry missing: %v", err)
+	}
+	if string(b) != "prompt copied from chat\n" {
+		t.Fatalf("unexpected content: %q", string(b))
+	}
+}
+
+func TestAddFromEmptyClipboardFails(t *testing.T) {
+	bin := buildBinary(t)
+	setFakeClipboard(t, "  \n")
+
+	out, err := exec.Command(bin, "add", "from_empty_clip", "--from-clipboard").CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected empty clipboard to fail, got: %s", out)
+	}
+	if !strings.Contains(string(out), "empty content") {
+		t.Fatalf("expected empty content error, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(storePath(), "from_empty_clip.md")); !os.IsNotExist(err) {
+		t.Fatalf("no entry should be created for empty clipboard")
+	}
+}
+
+func TestEditAppendClipboard(t *testing.T) {
+	bin := buildBinary(t)
+
+	add := exec.Command(bin, "add", "append_clip")
+	add.Stdin = strings.NewReader("first line")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	setFakeClipboard(t, "second line\n")
+	if out, err := exec.Command(bin, "edit", "append_clip", "--append-clipboard").CombinedOutput(); err != nil {
+		t.Fatalf("edit --append-clipboard failed: %v\n%s", err, out)
+	}
+
+	b, err := os.ReadFile(filepath.Join(storePath(), "append_clip.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "first line\nsecond line\n" {
+		t.Fatalf("unexpected content: %q", string(b))
+	}
+
+	log, err := exec.Command("git", "-C", storePath(), "log", "-n1", "--format=%s").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v\n%s", err, log)
+	}
+	if strings.TrimSpace(string(log)) != "feat: edit append_clip.md" {
+		t.Fatalf("unexpected last commit: %s", log)
+	}
+}
@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func copySensitive(t *testing.T, name, content string) string {
+	t.Helper()
+	bin := buildBinary(t)
+
+	add := exec.Command(bin, "add", name)
+	add.Stdin = strings.NewReader(content)
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	cp := exec.Command(bin, "cp", name)
+	cp.Env = append(os.Environ(), "PEA_CLIPBOARD_CLEAR=300ms")
+	out, err := cp.CombinedOutput()
+	if err != nil {
+		t.Fatalf("cp failed: %v\n%s", err, out)
+	}
+	return string(out)
+}
+
+func readFakeClipboard(t *testing.T) string {
+	t.Helper()
+	b, err := os.ReadFile(os.Getenv("PEA_FAKE_CLIP_FILE"))
+	if err != nil {
+		t.Fatalf("read fake clipboard: %v", err)
+	}
+	return string(b)
+}
+
+func TestClipboardClearedForSecretTag(t *testing.T) {
+	out := copySensitive(t, "clear_secret", "---\ntags: [secret]\n---\nhunter2\n")
+	if !strings.Contains(out, "clears in 300ms") {
+		t.Fatalf("expected clear notice, got: %s", out)
+	}
+	if got := readFakeClipboard(t); got != "hunter2\n" {
+		t.Fatalf("clipboard should hold the secret first, got %q", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for readFakeClipboard(t) != "" {
+		if time.Now().After(deadline) {
+			t.Fatalf("clipboard was not cleared")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestClipboardNotClearedAfterUserCopiesSomethingElse(t *testing.T) {
+	copySensitive(t, "clear_sensitive", "---\nsensitive: true\n---\ntoken\n")
+
+	if err := os.WriteFile(os.Getenv("PEA_FAKE_CLIP_FILE"), []byte("user copied this"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if got := readFakeClipboard(t); got != "user copied this" {
+		t.Fatalf("clipboard should keep the user's value, got %q", got)
+	}
+}
+
+func TestClipboardNotClearedForRegularEntries(t *testing.T) {
+	out := copySensitive(t, "clear_regular", "---\ntags: [work]\n---\nplain\n")
+	if strings.Contains(out, "clears in") {
+		t.Fatalf("regular entries should not be cleared: %s", out)
+	}
+
+	time.Sleep(1 * time.Second)
+	if got := readFakeClipboard(t); got != "plain\n" {
+		t.Fatalf("clipboard should still hold the entry, got %q", got)
+	}
+}
+
+func TestClipboardClearUnavailableForWriteOnlyProvider(t *testing.T) {
+	bin := buildBinary(t)
+	add := exec.Command(bin, "add", "clear_osc52")
+	add.Stdin = strings.NewReader("---\ntags: [secret]\n---\nhunter2\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	tty, _ := fakeTerminal(t)
+	cp := exec.Command(bin, "cp", "clear_osc52")
+	cp.Env = append(os.Environ(), tty, "PEA_CLIPBOARD_CLEAR=300ms", "PEA_CLIPBOARD=osc52", "TMUX=")
+	out, err := cp.CombinedOutput()
+	if err != nil {
+		t.Fatalf("cp failed: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "clears in") || !strings.Contains(string(out), "warning: auto-clear is unavailable: osc52 cannot read the clipboard back") {
+		t.Fatalf("expected an auto-clear warning, got: %q", out)
+	}
+}
+
+func TestGetSensitiveWithWriteOnlyProviderWarns(t *testing.T) {
+	script, err := exec.LookPath("script")
+	if err != nil {
+		t.Skip("script(1) is needed to give pea get a terminal")
+	}
+	bin := buildBinary(t)
+	add := exec.Command(bin, "add", "clear_get_osc52")
+	add.Stdin = strings.NewReader("---\nsensitive: true\n---\nhunter2\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	// get only copies when stdout is a terminal, which script provides.
+	tty, _ := fakeTerminal(t)
+	get := exec.Command(script, "-qec", "'"+bin+"' get clear_get_osc52", "/dev/null")
+	get.Env = append(os.Environ(), tty, "PEA_CLIPBOARD_CLEAR=300ms", "PEA_CLIPBOARD=osc52", "TMUX=")
+	out, err := get.CombinedOutput()
+	if err != nil {
+		t.Fatalf("get failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "warning: auto-clear is unavailable: osc52 cannot read the clipboard back") {
+		t.Fatalf("expected an auto-clear warning, got: %q", out)
+	}
+}
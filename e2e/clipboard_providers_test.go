@@ -0,0 +1,209 @@
+package e2e
+
+import (
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClipboardTools installs stand-ins for the external clipboard tools.
+// Each one records its stdin and arguments under the returned directory.
+func fakeClipboardTools(t *testing.T) (binDir, outDir string) {
+	t.Helper()
+	binDir = t.TempDir()
+	outDir = t.TempDir()
+	for _, tool := range []string{"wl-copy", "xclip", "xsel", "tmux"} {
+		script := "#!/bin/sh\necho \"$@\" > '" + filepath.Join(outDir, tool+".args") + "'\ncat > '" + filepath.Join(outDir, tool) + "'\n"
+		if err := os.WriteFile(filepath.Join(binDir, tool), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return binDir, outDir
+}
+
+// copyWithEnv runs 'pea cp' for a fresh entry with extra environment and
+// returns the combined output.
+func copyWithEnv(t *testing.T, name, content string, env ...string) string {
+	t.Helper()
+	bin := buildBinary(t)
+
+	add := exec.Command(bin, "add", name)
+	add.Stdin = strings.NewReader(content)
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	cp := exec.Command(bin, "cp", name)
+	cp.Env = append(os.Environ(), env...)
+	out, err := cp.CombinedOutput()
+	if err != nil {
+		t.Fatalf("cp failed: %v\n%s", err, out)
+	}
+	return string(out)
+}
+
+// fakeTerminal gives headless pea a terminal for OSC 52. It returns the
+// environment entry to pass and the file that receives the output.
+func fakeTerminal(t *testing.T) (env, file string) {
+	t.Helper()
+	file = filepath.Join(t.TempDir(), "tty")
+	return "PEA_FAKE_TTY_FILE=" + file, file
+}
+
+// readFile returns the content of p, or "" if it doesn't exist.
+func readFile(t *testing.T, p string) string {
+	t.Helper()
+	b, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestClipboardCommandProviders(t *testing.T) {
+	binDir, outDir := fakeClipboardTools(t)
+	path := "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")
+
+	cases := map[string]string{
+		"wl-copy": "",
+		"xclip":   "-selection clipboard -in",
+		"xsel":    "--clipboard --input",
+		"tmux":    "load-buffer -w -",
+	}
+	for tool, wantArgs := range cases {
+		t.Run(tool, func(t *testing.T) {
+			content := "copied via " + tool + "\n"
+			copyWithEnv(t, "clip_"+strings.ReplaceAll(tool, "-", "_"), content, path, "PEA_CLIPBOARD="+tool)
+
+			got, err := os.ReadFile(filepath.Join(outDir, tool))
+			if err != nil {
+				t.Fatalf("%s was not invoked: %v", tool, err)
+			}
+			if string(got) != content {
+				t.Fatalf("%s received %q, want %q", tool, got, content)
+			}
+			args, _ := os.ReadFile(filepath.Join(outDir, tool+".args"))
+			if strings.TrimSpace(string(args)) != wantArgs {
+				t.Fatalf("%s args = %q, want %q", tool, strings.TrimSpace(string(args)), wantArgs)
+			}
+		})
+	}
+}
+
+func TestClipboardOSC52EmitsEscapeSequence(t *testing.T) {
+	tty, ttyFile := fakeTerminal(t)
+	out := copyWithEnv(t, "clip_osc52", "over ssh\n", tty, "PEA_CLIPBOARD=osc52", "TMUX=")
+
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("over ssh\n")) + "\a"
+	if got := readFile(t, ttyFile); got != want {
+		t.Fatalf("expected OSC 52 sequence %q on the terminal, got %q", want, got)
+	}
+	if strings.Contains(out, "\x1b]52;") {
+		t.Fatalf("OSC 52 must go to the terminal, not the output: %q", out)
+	}
+}
+
+func TestClipboardAutoDetectsWayland(t *testing.T) {
+	binDir, outDir := fakeClipboardTools(t)
+	copyWithEnv(t, "clip_auto_wayland", "wayland\n",
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"PEA_CLIPBOARD=auto", "WAYLAND_DISPLAY=wayland-0", "DISPLAY=", "TMUX=", "SSH_CONNECTION=", "SSH_TTY=")
+
+	got, err := os.ReadFile(filepath.Join(outDir, "wl-copy"))
+	if err != nil || string(got) != "wayland\n" {
+		t.Fatalf("expected wl-copy to receive content, got %q (err=%v)", got, err)
+	}
+}
+
+func TestClipboardAutoPrefersOSC52OverSSH(t *testing.T) {
+	binDir, outDir := fakeClipboardTools(t)
+	tty, ttyFile := fakeTerminal(t)
+	copyWithEnv(t, "clip_auto_ssh", "remote\n", tty,
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"PEA_CLIPBOARD=auto", "WAYLAND_DISPLAY=wayland-0", "SSH_CONNECTION=10.0.0.1 22 10.0.0.2 22", "TMUX=")
+
+	if got := readFile(t, ttyFile); !strings.HasPrefix(got, "\x1b]52;c;") {
+		t.Fatalf("expected OSC 52 over SSH, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "wl-copy")); err == nil {
+		t.Fatalf("wl-copy should not be used when OSC 52 succeeds")
+	}
+}
+
+func TestClipboardConfigOverride(t *testing.T) {
+	binDir, outDir := fakeClipboardTools(t)
+	home, _ := peaHome(t, "clipboard = \"xsel\"\n")
+
+	runPea(t, home, "from config\n", "add", "clip_config")
+	c := exec.Command(buildBinary(t), "cp", "clip_config")
+	c.Env = append(os.Environ(), "HOME="+home, "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("cp failed: %v\n%s", err, out)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outDir, "xsel"))
+	if err != nil || string(got) != "from config\n" {
+		t.Fatalf("expected xsel to receive content, got %q (err=%v)", got, err)
+	}
+}
+
+func TestClipboardUnknownProviderFails(t *testing.T) {
+	bin := buildBinary(t)
+	add := exec.Command(bin, "add", "clip_unknown")
+	add.Stdin = strings.NewReader("x\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	cp := exec.Command(bin, "cp", "clip_unknown")
+	cp.Env = append(os.Environ(), "PEA_CLIPBOARD=carrier-pigeon")
+	out, err := cp.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "unknown clipboard") {
+		t.Fatalf("expected unknown clipboard error, got err=%v: %s", err, out)
+	}
+}
+
+func TestClipboardCommandThatForksDoesNotBlock(t *testing.T) {
+	binDir := t.TempDir()
+	// Like xclip, stay in the background serving the selection with the
+	// inherited stdout and stderr.
+	script := "#!/bin/sh\ncat > /dev/null\n(sleep 30) &\n"
+	if err := os.WriteFile(filepath.Join(binDir, "xclip"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	copyWithEnv(t, "clip_forking", "forked\n", "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"), "PEA_CLIPBOARD=xclip")
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("cp waited %s for the forked clipboard tool", d)
+	}
+}
+
+func TestClipboardOSC52WithoutTerminalFallsThrough(t *testing.T) {
+	binDir, outDir := fakeClipboardTools(t)
+	bin := buildBinary(t)
+	add := exec.Command(bin, "add", "clip_no_tty")
+	add.Stdin = strings.NewReader("no tty\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	// Without PEA_FAKE_TTY_FILE headless pea has no terminal.
+	cp := exec.Command(bin, "cp", "clip_no_tty")
+	cp.Env = append(os.Environ(), "PEA_FAKE_TTY_FILE=", "PEA_CLIPBOARD=auto", "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SSH_CONNECTION=10.0.0.1 22 10.0.0.2 22", "WAYLAND_DISPLAY=wayland-0", "DISPLAY=", "TMUX=")
+	out, err := cp.CombinedOutput()
+	if err != nil {
+		t.Fatalf("cp failed: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "\x1b]52;") {
+		t.Fatalf("OSC 52 must not go to the output without a terminal: %q", out)
+	}
+	if got, _ := os.ReadFile(filepath.Join(outDir, "wl-copy")); string(got) != "no tty\n" {
+		t.Fatalf("expected the chain to fall through to wl-copy, got %q", got)
+	}
+}
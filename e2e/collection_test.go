@@ -0,0 +1,112 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectionRendersMembersInOrder(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "You are a senior engineer.\n", "add", "coll_persona")
+	runPea(t, home, "---\nvars:\n  lang: go\n---\nReview this {{lang}} code for {{focus}}.\n", "add", "coll_task")
+	runPea(t, home, "Answer in bullet points.\n", "add", "coll_format")
+	runPea(t, home, "---\ntype: collection\nseparator: \"\\n---\\n\"\n---\n- coll_persona\n- coll_task lang=rust focus=\"error handling\"\n# formatting last\n- coll_format\n", "add", "coll_review")
+
+	want := "You are a senior engineer.\n\n---\nReview this rust code for error handling.\n\n---\nAnswer in bullet points.\n"
+	if out := runPea(t, home, "", "get", "coll_review"); out != want {
+		t.Fatalf("unexpected collection output: %q", out)
+	}
+	if out := runPea(t, home, "", "get", "coll_review", "--var", "lang=zig"); !strings.Contains(out, "Review this zig code") {
+		t.Fatalf("--var should override member vars: %q", out)
+	}
+	if out := runPea(t, home, "", "deps", "coll_review"); out != "coll_review\n  coll_persona\n  coll_task\n  coll_format\n" {
+		t.Fatalf("unexpected deps: %q", out)
+	}
+	if out := runPea(t, home, "", "ls", "-l"); !strings.Contains(out, "coll_review (collection)") || strings.Contains(out, "coll_task (collection)") {
+		t.Fatalf("ls -l should mark collections:\n%s", out)
+	}
+	if out := runPea(t, home, "", "ls"); !strings.Contains(out, "coll_review\n") {
+		t.Fatalf("plain ls should print bare names when piped:\n%s", out)
+	}
+}
+
+func TestCollectionMemberRenameAndLint(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "Persona.\n", "add", "coll_old")
+	runPea(t, home, "Task.\n", "add", "coll_other")
+	runPea(t, home, "---\ntype: collection\n---\n- coll_old\ncoll_other\n", "add", "coll_bundle")
+
+	runPea(t, home, "", "mv", "coll_old", "coll_new")
+	store := filepath.Join(home, ".pea", "prompts")
+	b, err := os.ReadFile(filepath.Join(store, "coll_bundle.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "---\ntype: collection\n---\n- coll_new\ncoll_other\n" {
+		t.Fatalf("mv should update collection members: %q", b)
+	}
+	files, err := exec.Command("git", "-C", store, "show", "--name-only", "--format=%s", "HEAD").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git show: %v\n%s", err, files)
+	}
+	if !strings.Contains(string(files), "coll_bundle.md") || !strings.Contains(string(files), "coll_new.md") {
+		t.Fatalf("rename and member update should share a commit:\n%s", files)
+	}
+	if out := runPea(t, home, "", "get", "coll_bundle"); out != "Persona.\n\nTask.\n" {
+		t.Fatalf("unexpected output after rename: %q", out)
+	}
+
+	runPea(t, home, "", "rm", "coll_other")
+	if out := peaFails(t, home, "get", "coll_bundle"); !strings.Contains(out, "collection coll_bundle: not found: coll_other") {
+		t.Fatalf("expected missing member error:\n%s", out)
+	}
+	if out := peaFails(t, home, "lint", "coll_bundle"); !strings.Contains(out, "coll_bundle.md:5") || !strings.Contains(out, "member not found: coll_other") {
+		t.Fatalf("lint should report the missing member:\n%s", out)
+	}
+}
+
+func TestCollectionCycle(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "---\ntype: collection\n---\ncoll_cycle_b\n", "add", "coll_cycle_a")
+	runPea(t, home, "---\ntype: collection\n---\ncoll_cycle_a\n", "add", "coll_cycle_b")
+
+	if out := peaFails(t, home, "get", "coll_cycle_a"); !strings.Contains(out, "collection cycle: coll_cycle_a -> coll_cycle_b -> coll_cycle_a") {
+		t.Fatalf("expected cycle error:\n%s", out)
+	}
+}
+
+func TestCollectionPassesMembersOnlyTheirVars(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "---\nvars:\n  lang: go\n---\nWrite {{lang}}.\n", "add", "coll_vars_task")
+	runPea(t, home, "code: {{ literal }}\n", "add", "coll_vars_plain")
+	runPea(t, home, "---\ntype: collection\n---\ncoll_vars_task lang=rust\ncoll_vars_plain\n", "add", "coll_vars")
+
+	want := "Write py.\n\ncode: {{ literal }}\n"
+	if out := runPea(t, home, "", "get", "coll_vars", "--var", "lang=py"); out != want {
+		t.Fatalf("members without vars should render unchanged: %q", out)
+	}
+}
+
+func TestIncludeRendersCollection(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "Persona.\n", "add", "coll_inc_persona")
+	runPea(t, home, "---\nvars:\n  lang: go\n---\nWrite {{lang}}.\n", "add", "coll_inc_task")
+	runPea(t, home, "---\ntype: collection\n---\ncoll_inc_persona\ncoll_inc_task\n", "add", "coll_inc")
+	runPea(t, home, "Start\n{{> coll_inc}}\nEnd\n", "add", "coll_inc_outer")
+
+	if out := runPea(t, home, "", "get", "coll_inc_outer"); out != "Start\nPersona.\n\nWrite go.\nEnd\n" {
+		t.Fatalf("include should render the collection: %q", out)
+	}
+	if out := runPea(t, home, "", "deps", "coll_inc_outer"); out != "coll_inc_outer\n  coll_inc\n    coll_inc_persona\n    coll_inc_task\n" {
+		t.Fatalf("unexpected deps: %q", out)
+	}
+
+	runPea(t, home, "Loop {{> coll_loop}}\n", "add", "coll_loop_member")
+	runPea(t, home, "---\ntype: collection\n---\ncoll_loop_member\n", "add", "coll_loop")
+	if out := peaFails(t, home, "get", "coll_loop"); !strings.Contains(out, "cycle: coll_loop -> coll_loop_member -> coll_loop") {
+		t.Fatalf("expected cycle error through the include:\n%s", out)
+	}
+}
@@ -0,0 +1,87 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompletionExtraShells(t *testing.T) {
+	bin := buildBinary(t)
+
+	cases := map[string]string{
+		"fish":       "complete -c pea",
+		"powershell": "Register-ArgumentCompleter",
+		"nushell":    "__complete",
+	}
+	for shell, want := range cases {
+		out, err := exec.Command(bin, "completion", shell).CombinedOutput()
+		if err != nil {
+			t.Fatalf("completion %s failed: %v\n%s", shell, err, out)
+		}
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("completion %s missing %q:\n%s", shell, want, out)
+		}
+	}
+
+	// nushell has a single global external completer; other commands must
+	// still reach the one configured before.
+	out, err := exec.Command(bin, "completion", "nushell").CombinedOutput()
+	if err != nil {
+		t.Fatalf("completion nushell failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "do $pea_previous_completer $spans") {
+		t.Fatalf("nushell completion should chain to the previous completer:\n%s", out)
+	}
+}
+
+func TestCompletionInstallFish(t *testing.T) {
+	bin := buildBinary(t)
+	home := t.TempDir()
+
+	cmd := exec.Command(bin, "completion", "install")
+	cmd.Env = append(os.Environ(), "HOME="+home, "SHELL=/usr/bin/fish", "XDG_CONFIG_HOME=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("install failed: %v\n%s", err, out)
+	}
+
+	b, err := os.ReadFile(filepath.Join(home, ".config", "fish", "completions", "pea.fish"))
+	if err != nil {
+		t.Fatalf("fish completion not installed: %v", err)
+	}
+	if !strings.Contains(string(b), "complete -c pea") {
+		t.Fatalf("unexpected fish completion:\n%s", b)
+	}
+}
+
+func TestCompletionTagsAndRevisions(t *testing.T) {
+	bin := buildBinary(t)
+
+	add := exec.Command(bin, "add", "comp_tagged")
+	add.Stdin = strings.NewReader("---\ntags: [compalpha, compbeta]\n---\nbody\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+
+	out, err := exec.Command(bin, "__complete", "search", "--tag", "compa").CombinedOutput()
+	if err != nil {
+		t.Fatalf("tag completion failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "compalpha") || strings.Contains(string(out), "compbeta") {
+		t.Fatalf("unexpected tag completion:\n%s", out)
+	}
+
+	sha, err := exec.Command("git", "-C", storePath(), "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	out, err = exec.Command(bin, "__complete", "get", "comp_tagged", "--rev", "").CombinedOutput()
+	if err != nil {
+		t.Fatalf("rev completion failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), strings.TrimSpace(string(sha))+"\tfeat: add comp_tagged.md") {
+		t.Fatalf("expected %s in rev completion:\n%s", sha, out)
+	}
+}
@@ -0,0 +1,105 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const concurrentAdds = 8
+
+func TestConcurrentAddsAllCommitted(t *testing.T) {
+	bin := buildBinary(t)
+	store := t.TempDir()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentAdds)
+	for i := 0; i < concurrentAdds; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := exec.Command(bin, "add", fmt.Sprintf("parallel_%d", i))
+			c.Env = append(os.Environ(), "PEA_STORE="+store)
+			c.Stdin = strings.NewReader(fmt.Sprintf("content %d\n", i))
+			out, err := c.CombinedOutput()
+			if err != nil {
+				errs <- fmt.Errorf("add %d: %v\n%s", i, err, out)
+				return
+			}
+			if strings.Contains(string(out), "warning") {
+				errs <- fmt.Errorf("add %d warned: %s", i, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for i := 0; i < concurrentAdds; i++ {
+		b, err := os.ReadFile(filepath.Join(store, fmt.Sprintf("parallel_%d.md", i)))
+		if err != nil {
+			t.Fatalf("entry %d missing: %v", i, err)
+		}
+		if string(b) != fmt.Sprintf("content %d\n", i) {
+			t.Fatalf("entry %d has wrong content: %q", i, string(b))
+		}
+	}
+
+	log, err := exec.Command("git", "-C", store, "log", "--format=%s").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v\n%s", err, log)
+	}
+	if n := strings.Count(string(log), "feat: add parallel_"); n != concurrentAdds {
+		t.Fatalf("expected %d commits, got %d:\n%s", concurrentAdds, n, log)
+	}
+
+	status, err := exec.Command("git", "-C", store, "status", "--porcelain").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git status: %v\n%s", err, status)
+	}
+	if strings.TrimSpace(string(status)) != "" {
+		t.Fatalf("store should be clean after concurrent adds:\n%s", status)
+	}
+}
+
+func TestConcurrentWritesToSameEntryAreNotTorn(t *testing.T) {
+	bin := buildBinary(t)
+	store := t.TempDir()
+
+	contents := make([]string, concurrentAdds)
+	for i := range contents {
+		contents[i] = strings.Repeat(fmt.Sprintf("writer %d line\n", i), 2000)
+	}
+
+	var wg sync.WaitGroup
+	for i := range contents {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := exec.Command(bin, "add", "shared_entry")
+			c.Env = append(os.Environ(), "PEA_STORE="+store)
+			c.Stdin = strings.NewReader(contents[i])
+			if out, err := c.CombinedOutput(); err != nil {
+				t.Errorf("add %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(filepath.Join(store, "shared_entry.md"))
+	if err != nil {
+		t.Fatalf("read entry: %v", err)
+	}
+	for _, c := range contents {
+		if string(b) == c {
+			return
+		}
+	}
+	t.Fatalf("entry content is torn (%d bytes)", len(b))
+}
@@ -0,0 +1,79 @@
+package e2e
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const evalConfig = `[runners]
+mock = 'sed -E "s/^Reply in (.*)\.$/{\"lang\": \"\1\", \"score\": 7}/"'
+echo = "cat"
+`
+
+func TestEvalTestsPassAndFail(t *testing.T) {
+	home, _ := peaHome(t, evalConfig)
+	runPea(t, home, "---\nvars:\n  lang: go\n---\nReply in {{lang}}.\n", "add", "eval_reply")
+	store := filepath.Join(home, ".pea", "prompts")
+	writeStoreFile(t, store, "eval_reply.tests.toml", `runner = "mock"
+
+[[case]]
+name = "default language"
+contains = ["\"go\""]
+json_schema = '''
+{"type": "object", "required": ["lang", "score"],
+ "properties": {"score": {"type": "integer", "minimum": 1, "maximum": 10}}}
+'''
+
+[[case]]
+name = "ruby"
+vars = { lang = "ruby" }
+regex = ['"lang": "ruby"']
+not_contains = ["python"]
+`)
+
+	out := runPea(t, home, "", "test", "eval_reply")
+	for _, want := range []string{"PASS eval_reply: default language", "PASS eval_reply: ruby", "2 passed, 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// The echo runner returns the prompt itself, which is not JSON.
+	out = peaFails(t, home, "test", "eval_reply", "--runner", "echo")
+	for _, want := range []string{
+		"FAIL eval_reply: default language",
+		"output is not valid JSON",
+		"expected output to contain \"\\\"go\\\"\"",
+		"    Reply in go.",
+		"0 passed, 2 failed",
+		"2 of 2 test cases failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestEvalTestsAllEntriesWithCommand(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "Summarize {{topic}}\n", "add", "eval_summary")
+	runPea(t, home, "no tests here\n", "add", "eval_untested")
+	store := filepath.Join(home, ".pea", "prompts")
+	writeStoreFile(t, store, "eval_summary.tests.toml", `[[case]]
+vars = { topic = "channels" }
+contains = ["channels"]
+`)
+
+	out := runPea(t, home, "", "test", "--", "cat")
+	if !strings.Contains(out, "PASS eval_summary: case 1") || strings.Contains(out, "eval_untested") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	if out := peaFails(t, home, "test", "eval_untested", "--", "cat"); !strings.Contains(out, "no tests for eval_untested") {
+		t.Fatalf("expected missing tests error:\n%s", out)
+	}
+	if out := peaFails(t, home, "test", "eval_summary"); !strings.Contains(out, "no runner configured") {
+		t.Fatalf("expected runner error:\n%s", out)
+	}
+}
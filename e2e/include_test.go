@@ -0,0 +1,134 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEntry(t *testing.T, name, content string) {
+	t.Helper()
+	store := storePath()
+	if err := os.MkdirAll(store, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store, name+".md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func peaOutput(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	out, err := exec.Command(buildBinary(t), args...).CombinedOutput()
+	return string(out), err
+}
+
+func TestGetExpandsNestedIncludes(t *testing.T) {
+	writeEntry(t, "inc_style", "Be terse.\n")
+	writeEntry(t, "inc_role", "---\ndescription: role\n---\nYou are a reviewer.\n{{> inc_style}}\n")
+	writeEntry(t, "inc_main", "{{> inc_role}}\nReview this.\n")
+
+	out, err := peaOutput(t, "get", "inc_main")
+	if err != nil {
+		t.Fatalf("get failed: %v\n%s", err, out)
+	}
+	if out != "You are a reviewer.\nBe terse.\nReview this.\n" {
+		t.Fatalf("unexpected expansion: %q", out)
+	}
+
+	raw, err := peaOutput(t, "get", "inc_main", "--raw")
+	if err != nil {
+		t.Fatalf("get --raw failed: %v\n%s", err, raw)
+	}
+	if raw != "{{> inc_role}}\nReview this.\n" {
+		t.Fatalf("--raw should not expand includes: %q", raw)
+	}
+
+	deps, err := peaOutput(t, "deps", "inc_main")
+	if err != nil {
+		t.Fatalf("deps failed: %v\n%s", err, deps)
+	}
+	if deps != "inc_main\n  inc_role\n    inc_style\n" {
+		t.Fatalf("unexpected deps output: %q", deps)
+	}
+}
+
+func TestGetRejectsIncludeCycles(t *testing.T) {
+	writeEntry(t, "inc_cycle_a", "A\n{{> inc_cycle_b}}\n")
+	writeEntry(t, "inc_cycle_b", "B\n{{> inc_cycle_a}}\n")
+
+	out, err := peaOutput(t, "get", "inc_cycle_a")
+	if err == nil {
+		t.Fatalf("expected cycle error, got: %s", out)
+	}
+	if !strings.Contains(out, "include cycle: inc_cycle_a -> inc_cycle_b -> inc_cycle_a") {
+		t.Fatalf("unexpected error: %s", out)
+	}
+
+	deps, err := peaOutput(t, "deps", "inc_cycle_a")
+	if err != nil {
+		t.Fatalf("deps failed: %v\n%s", err, deps)
+	}
+	if !strings.Contains(deps, "inc_cycle_a (cycle)") {
+		t.Fatalf("deps should mark the cycle: %q", deps)
+	}
+}
+
+func TestGetReportsMissingInclude(t *testing.T) {
+	writeEntry(t, "inc_broken", "{{> inc_does_not_exist}}\n")
+
+	out, err := peaOutput(t, "get", "inc_broken")
+	if err == nil || !strings.Contains(out, "include in inc_broken: not found: inc_does_not_exist") {
+		t.Fatalf("expected missing include error, got err=%v: %s", err, out)
+	}
+}
+
+func TestIncludePinnedToRevision(t *testing.T) {
+	bin := buildBinary(t)
+
+	add := exec.Command(bin, "add", "inc_pinned")
+	add.Stdin = strings.NewReader("version one\n")
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("add failed: %v\n%s", err, out)
+	}
+	sha, err := exec.Command("git", "-C", storePath(), "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+
+	add2 := exec.Command(bin, "add", "inc_pinned")
+	add2.Stdin = strings.NewReader("version two\n")
+	if out, err := add2.CombinedOutput(); err != nil {
+		t.Fatalf("second add failed: %v\n%s", err, out)
+	}
+
+	writeEntry(t, "inc_uses_pinned", "old: {{> inc_pinned@"+strings.TrimSpace(string(sha))+"}}\nnew: {{> inc_pinned}}\n")
+
+	out, err := peaOutput(t, "get", "inc_uses_pinned")
+	if err != nil {
+		t.Fatalf("get failed: %v\n%s", err, out)
+	}
+	if out != "old: version one\nnew: version two\n" {
+		t.Fatalf("unexpected pinned expansion: %q", out)
+	}
+}
+
+func TestIncludeRevCannotInjectGitOptions(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "pwned")
+	writeEntry(t, "inc_evil", "{{> inc_style@--output="+target+"}}\n")
+
+	out, err := peaOutput(t, "get", "inc_evil")
+	if err == nil || !strings.Contains(out, `invalid revision "--output=`) {
+		t.Fatalf("expected invalid revision error, got err=%v: %s", err, out)
+	}
+	if matches, _ := filepath.Glob(target + "*"); len(matches) > 0 {
+		t.Fatalf("git wrote %v", matches)
+	}
+
+	out, err = peaOutput(t, "get", "inc_style", "--rev", "no_such_rev")
+	if err == nil || !strings.Contains(out, "not found in ref no_such_rev: inc_style") {
+		t.Fatalf("expected not found error, got err=%v: %s", err, out)
+	}
+}
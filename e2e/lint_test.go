@@ -0,0 +1,158 @@
+package e2e
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLintReportsProblems(t *testing.T) {
+	home, store := peaHome(t, "")
+	runPea(t, home, "clean\n", "add", "lint_clean")
+	writeStoreFile(t, store, "lint_open.md", "---\ntags: [a]\nbody\n")
+	writeStoreFile(t, store, "lint_bad.md", "---\nnot a key\nvars: [lang]\n---\n{{lang}} {{topic}}\n{{> lint_missing}}\n")
+	writeStoreFile(t, store, "lint_dup.md", "md\n")
+	writeStoreFile(t, store, "lint_dup.txt", "txt\n")
+	writeStoreFile(t, store, "lint_space.md", "trailing \n")
+
+	c := exec.Command(buildBinary(t), "lint")
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if code := exitCode(err); code != 1 {
+		t.Fatalf("lint should exit 1 on errors, got %d:\n%s", code, out)
+	}
+	for _, want := range []string{
+		"lint_open.md:1: error: front matter is not closed",
+		"lint_bad.md:2: error: malformed front matter line",
+		"lint_bad.md:5: error: template variable \"topic\" is not declared",
+		"lint_bad.md: error: include in lint_bad: not found: lint_missing",
+		"lint_dup.md: error: lint_dup.txt is shadowed by lint_dup.md",
+		"lint_space.md:1: warning: trailing whitespace",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("lint output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "lint_clean") {
+		t.Errorf("clean entry should not be reported:\n%s", out)
+	}
+
+	c = exec.Command(buildBinary(t), "lint", "--json", "lint_space")
+	c.Env = append(os.Environ(), "HOME="+home)
+	jsonOut, err := c.Output()
+	if err != nil {
+		t.Fatalf("warnings alone should not fail lint: %v\n%s", err, jsonOut)
+	}
+	var report struct {
+		Checked  int `json:"checked"`
+		Warnings int `json:"warnings"`
+		Problems []struct {
+			File  string `json:"file"`
+			Line  int    `json:"line"`
+			Check string `json:"check"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal(jsonOut, &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, jsonOut)
+	}
+	if report.Checked != 1 || report.Warnings != 1 || len(report.Problems) != 1 || report.Problems[0].Check != "whitespace" || report.Problems[0].Line != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	c = exec.Command(buildBinary(t), "lint", "--strict", "lint_space.md")
+	c.Env = append(os.Environ(), "HOME="+home)
+	if out, err := c.CombinedOutput(); exitCode(err) != 2 {
+		t.Fatalf("--strict should exit 2 on warnings alone, got %v:\n%s", err, out)
+	}
+
+	for _, args := range [][]string{{"lint", "--no-such-flag"}, {"lint", "lint_nowhere"}} {
+		c = exec.Command(buildBinary(t), args...)
+		c.Env = append(os.Environ(), "HOME="+home)
+		if out, err := c.CombinedOutput(); exitCode(err) != 3 {
+			t.Fatalf("pea %v should exit 3, got %v:\n%s", args, err, out)
+		}
+	}
+}
+
+// exitCode returns the exit status of a finished command, 0 for success.
+func exitCode(err error) int {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+func TestLintAsPreCommitHook(t *testing.T) {
+	home, store := peaHome(t, "")
+	runPea(t, home, "clean\n", "add", "lint_clean")
+	hook := "#!/bin/sh\nfiles=$(git diff --cached --name-only --diff-filter=ACM -- '*.md' '*.txt')\n[ -z \"$files\" ] || exec '" + buildBinary(t) + "' lint --staged $files\n"
+	if err := os.WriteFile(filepath.Join(store, ".git", "hooks", "pre-commit"), []byte(hook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	runPea(t, home, "fine\n", "add", "hook_good")
+	if time.Since(start) > 10*time.Second {
+		t.Fatalf("hook should share the store lock instead of waiting for it")
+	}
+	log, _ := exec.Command("git", "-C", store, "log", "-n1", "--format=%s").Output()
+	if strings.TrimSpace(string(log)) != "feat: add hook_good.md" {
+		t.Fatalf("clean entry should be committed, last commit: %s", log)
+	}
+
+	out := runPea(t, home, "---\ntags: [a]\nunclosed\n", "add", "hook_bad")
+	if !strings.Contains(out, "front matter is not closed") {
+		t.Fatalf("hook should report the lint error:\n%s", out)
+	}
+	log, _ = exec.Command("git", "-C", store, "log", "-n1", "--format=%s").Output()
+	if strings.TrimSpace(string(log)) != "feat: add hook_good.md" {
+		t.Fatalf("broken entry should not be committed, last commit: %s", log)
+	}
+
+	// The hook checks what is staged, not a fixed-up working tree.
+	writeStoreFile(t, store, "hook_bad.md", "fixed\n")
+	commit := func(args ...string) ([]byte, error) {
+		c := exec.Command("git", append([]string{"-C", store, "commit"}, args...)...)
+		c.Env = append(os.Environ(), "HOME="+home)
+		return c.CombinedOutput()
+	}
+	if out, err := commit("-m", "chore: sneak in"); err == nil || !strings.Contains(string(out), "front matter is not closed") {
+		t.Fatalf("hook should lint the staged content: %v\n%s", err, out)
+	}
+	if out, err := commit("-a", "-m", "chore: fixed"); err != nil {
+		t.Fatalf("staging the fix should let the commit through: %v\n%s", err, out)
+	}
+}
+
+func TestLintUndeclaredVarsAndHookPaths(t *testing.T) {
+	home, store := peaHome(t, "")
+	runPea(t, home, "clean\n", "add", "lint_clean")
+	writeStoreFile(t, store, "lint_literal.md", "code: {{ literal }}\n")
+	writeStoreFile(t, store, "lint_literal.tests.toml", "[[case]]\n")
+
+	c := exec.Command(buildBinary(t), "lint", "lint_literal.md", "gone.md", ".pins", "lint_literal.tests.toml")
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("deleted and non-entry paths should be skipped, warnings alone should pass: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "lint_literal.md:1: warning: template variable \"literal\" is used but the entry declares no vars") {
+		t.Fatalf("expected a warning for an entry without vars:\n%s", out)
+	}
+	if strings.Contains(string(out), "gone") || strings.Contains(string(out), "tests.toml") {
+		t.Fatalf("skipped paths should not be reported:\n%s", out)
+	}
+
+	if out := peaFails(t, home, "lint", "gone"); !strings.Contains(out, "not found: gone") {
+		t.Fatalf("a missing entry named without extension should still fail:\n%s", out)
+	}
+}
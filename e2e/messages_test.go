@@ -0,0 +1,109 @@
+package e2e
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func getMessages(t *testing.T, home string, args ...string) []message {
+	t.Helper()
+	out := runPea(t, home, "", append([]string{"get", "--format", "messages-json"}, args...)...)
+	var msgs []message
+	if err := json.Unmarshal([]byte(out), &msgs); err != nil {
+		t.Fatalf("invalid messages JSON: %v\n%s", err, out)
+	}
+	return msgs
+}
+
+const chatPrompt = `---
+vars: [lang]
+---
+## system
+You review {{lang}} code.
+
+## user
+Here is an example:
+
+` + "```" + `markdown
+## user
+not a heading inside a fence
+` + "```" + `
+
+## assistant
+Understood.
+
+## User
+Review this.
+`
+
+func TestGetMessagesJSON(t *testing.T) {
+	home := t.TempDir()
+	runPea(t, home, chatPrompt, "add", "msg_chat")
+
+	msgs := getMessages(t, home, "msg_chat", "--var", "lang=Go")
+	want := []message{
+		{"system", "You review Go code."},
+		{"user", "Here is an example:\n\n```markdown\n## user\nnot a heading inside a fence\n```"},
+		{"assistant", "Understood."},
+		{"user", "Review this."},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(msgs), len(want), msgs)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("message %d = %+v, want %+v", i, msgs[i], want[i])
+		}
+	}
+}
+
+func TestGetMessagesJSONWithoutHeadings(t *testing.T) {
+	home := t.TempDir()
+	runPea(t, home, "---\nsystem: Be brief.\n---\nJust a question?\n", "add", "msg_plain")
+
+	msgs := getMessages(t, home, "msg_plain")
+	if len(msgs) != 2 || msgs[0] != (message{"system", "Be brief."}) || msgs[1] != (message{"user", "Just a question?"}) {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+}
+
+func TestGetMessagesJSONRejectsPreamble(t *testing.T) {
+	home := t.TempDir()
+	runPea(t, home, "stray text\n## user\nhi\n", "add", "msg_preamble")
+
+	out := peaFails(t, home, "get", "msg_preamble", "--format", "messages-json")
+	if !strings.Contains(out, "text before the first role heading") {
+		t.Fatalf("expected preamble error:\n%s", out)
+	}
+}
+
+func TestAskSendsRoleSections(t *testing.T) {
+	srv, got, _ := fakeChatAPI(t, true, "ok")
+	home, _ := peaHome(t, llmConfig(srv.URL))
+	runPea(t, home, "## system\nBe kind.\n\n## user\nHello\n", "add", "msg_ask")
+
+	runPea(t, home, "", "ask", "msg_ask")
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[0].Content != "Be kind." ||
+		got.Messages[1].Role != "user" || got.Messages[1].Content != "Hello" {
+		t.Fatalf("unexpected messages sent: %+v", got.Messages)
+	}
+}
+
+func TestGetMessagesJSONAtRevisionUsesItsFrontMatter(t *testing.T) {
+	home, store := peaHome(t, "")
+	runPea(t, home, "---\nsystem: Old rules.\n---\nHello.\n", "add", "msg_rev")
+	writeStoreFile(t, store, "msg_rev.md", "---\nsystem: New rules.\n---\nHello again.\n")
+	runPea(t, home, "", "commit", "-m", "chore: new rules")
+
+	msgs := getMessages(t, home, "msg_rev", "--rev", "HEAD~1")
+	want := []message{{"system", "Old rules."}, {"user", "Hello."}}
+	if len(msgs) != len(want) || msgs[0] != want[0] || msgs[1] != want[1] {
+		t.Fatalf("got %+v, want %+v", msgs, want)
+	}
+}
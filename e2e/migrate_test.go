@@ -0,0 +1,125 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// seedLegacyEntries commits .txt entries and .md/.txt pairs for migrate.
+func seedLegacyEntries(t *testing.T, home, store string) {
+	t.Helper()
+	runPea(t, home, "clean\n", "add", "lint_clean")
+	writeStoreFile(t, store, "legacy.txt", "---\ntags:[a]  \r\n---\r\nold text\r\n")
+	writeStoreFile(t, store, "twin.md", "same\n")
+	writeStoreFile(t, store, "twin.txt", "same\n")
+	writeStoreFile(t, store, "clash.md", "---\ntags: [a]\n---\nmd body\n")
+	writeStoreFile(t, store, "clash.txt", "---\ntags: [b]\ndescription: legacy\n---\ntxt body\n")
+	writeStoreFile(t, store, "untidy.md", "---\ntags:[c] \r\n---\r\nalone\r\n")
+	runPea(t, home, "", "commit", "-m", "legacy files")
+}
+
+func TestMigrateDryRunChangesNothing(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedLegacyEntries(t, home, store)
+
+	out := runPea(t, home, "", "migrate", "--dry-run")
+	for _, want := range []string{
+		"rename    legacy.txt -> legacy.md",
+		"drop      twin.txt (same as twin.md)",
+		"conflict  clash.txt differs from clash.md",
+		"normalize untidy.md front matter",
+		"dry-run: no changes made",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(store, "legacy.txt")); err != nil {
+		t.Fatalf("dry-run must not touch files: %v", err)
+	}
+}
+
+func TestMigrateConvertsInOneCommit(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedLegacyEntries(t, home, store)
+
+	runPea(t, home, "", "migrate")
+
+	b, err := os.ReadFile(filepath.Join(store, "legacy.md"))
+	if err != nil {
+		t.Fatalf("legacy.md missing: %v", err)
+	}
+	if string(b) != "---\ntags: [a]\n---\nold text\n" {
+		t.Fatalf("unexpected migrated content: %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(store, "untidy.md")); string(b) != "---\ntags: [c]\n---\nalone\n" {
+		t.Fatalf("an entry without a legacy twin should be normalized too: %q", b)
+	}
+	for _, gone := range []string{"legacy.txt", "twin.txt"} {
+		if _, err := os.Stat(filepath.Join(store, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", gone)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(store, "clash.txt")); err != nil {
+		t.Errorf("conflicting clash.txt should be left alone")
+	}
+
+	log, err := exec.Command("git", "-C", store, "log", "-n2", "--format=%s").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(log) != "chore: migrate 2 legacy entries to .md\nlegacy files\n" {
+		t.Fatalf("expected a single migration commit, got:\n%s", log)
+	}
+	if st, _ := exec.Command("git", "-C", store, "status", "--porcelain").Output(); len(st) != 0 {
+		t.Fatalf("migration left uncommitted changes:\n%s", st)
+	}
+}
+
+func TestMigrateMergesConflicts(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedLegacyEntries(t, home, store)
+
+	runPea(t, home, "", "migrate", "--merge")
+
+	b, err := os.ReadFile(filepath.Join(store, "clash.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ntags: [a, b]\ndescription: legacy\n---\nmd body\n\ntxt body\n"
+	if string(b) != want {
+		t.Fatalf("unexpected merge result:\n%q\nwant\n%q", b, want)
+	}
+	if _, err := os.Stat(filepath.Join(store, "clash.txt")); !os.IsNotExist(err) {
+		t.Fatalf("clash.txt should be merged away")
+	}
+	if out := runPea(t, home, "", "migrate"); !strings.Contains(out, "nothing to migrate") {
+		t.Fatalf("second run should be a no-op:\n%s", out)
+	}
+}
+
+func TestMigrateRestoresStoreWhenCommitFails(t *testing.T) {
+	home, store := peaHome(t, "")
+	seedLegacyEntries(t, home, store)
+	writeStoreFile(t, store, ".git/hooks/pre-commit", "#!/bin/sh\nexit 1\n")
+	if err := os.Chmod(filepath.Join(store, ".git", "hooks", "pre-commit"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := peaFails(t, home, "migrate"); !strings.Contains(out, "migrate failed") {
+		t.Fatalf("expected the failed commit to be reported:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(store, "legacy.md")); !os.IsNotExist(err) {
+		t.Errorf("legacy.md should be removed again")
+	}
+	if b, _ := os.ReadFile(filepath.Join(store, "untidy.md")); string(b) != "---\ntags:[c] \r\n---\r\nalone\r\n" {
+		t.Errorf("untidy.md should be restored: %q", b)
+	}
+	// Nothing staged, changed or left behind: the tree matches the last commit.
+	if st, _ := exec.Command("git", "-C", store, "status", "--porcelain", "--untracked-files=all").Output(); len(st) != 0 {
+		t.Fatalf("failed migration left changes behind:\n%s", st)
+	}
+}
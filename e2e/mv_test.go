@@ -47,3 +47,118 @@ func TestRenameEntry(t *testing.T) {
 		t.Fatalf("unexpected content: %q", string(b))
 	}
 }
+
+func gitOut(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestRenameUpdatesReferences(t *testing.T) {
+	home, _ := peaHome(t, "[aliases]\nrv = \"get ref_style --var lang=go\"\nother = \"get ref_style_extra\"\nfj = \"get ref_main --format ref_style\"\nbare = 'ref_style --raw'\n")
+	store := filepath.Join(home, ".pea", "prompts")
+	runPea(t, home, "Be terse.\n", "add", "ref_style")
+	runPea(t, home, "Extra.\n", "add", "ref_style_extra")
+	runPea(t, home, "{{> ref_style}}\n{{>ref_style }}\n{{> ref_style_extra}}\n", "add", "ref_main")
+	sha := strings.TrimSpace(gitOut(t, store, "rev-parse", "--short", "HEAD"))
+	runPea(t, home, "old: {{> ref_style@"+sha+"}}\n", "add", "ref_pinned")
+	runPea(t, home, "---\ntype: collection\n---\n- ref_style\n", "add", "ref_bundle")
+	runPea(t, home, "", "pin", "ref_style")
+	writeStoreFile(t, store, "ref_style.tests.toml", "[[case]]\ncontains = [\"terse\"]\n")
+
+	preview := runPea(t, home, "", "mv", "ref_style", "ref_tone", "--dry-run")
+	for _, want := range []string{
+		"dry-run: would rename ref_style.md to ref_tone.md",
+		"dry-run: would rename ref_style.tests.toml to ref_tone.tests.toml",
+		"dry-run: would update ref_bundle.md (1 collection member)",
+		"dry-run: would update ref_main.md (2 includes)",
+		"dry-run: would update .pins (1 pin)",
+		"dry-run: would update config.toml (2 aliases)",
+	} {
+		if !strings.Contains(preview, want) {
+			t.Fatalf("missing %q in preview:\n%s", want, preview)
+		}
+	}
+	if strings.Contains(preview, "ref_pinned") {
+		t.Fatalf("pinned includes should keep the old name:\n%s", preview)
+	}
+	if _, err := os.Stat(filepath.Join(store, "ref_style.md")); err != nil {
+		t.Fatalf("dry-run must not rename")
+	}
+
+	runPea(t, home, "", "mv", "ref_style", "ref_tone")
+	files := map[string]string{
+		"ref_main.md":   "{{> ref_tone}}\n{{>ref_tone }}\n{{> ref_style_extra}}\n",
+		"ref_bundle.md": "---\ntype: collection\n---\n- ref_tone\n",
+		".pins":         "ref_tone\n",
+	}
+	for file, want := range files {
+		if b, _ := os.ReadFile(filepath.Join(store, file)); string(b) != want {
+			t.Fatalf("%s not updated: %q", file, b)
+		}
+	}
+	if b, _ := os.ReadFile(filepath.Join(home, ".pea", "config.toml")); !strings.Contains(string(b), `rv = "get ref_tone --var lang=go"`) || !strings.Contains(string(b), `other = "get ref_style_extra"`) ||
+		!strings.Contains(string(b), `fj = "get ref_main --format ref_style"`) || !strings.Contains(string(b), `bare = "ref_tone --raw"`) {
+		t.Fatalf("aliases not updated:\n%s", b)
+	}
+	if _, err := os.Stat(filepath.Join(store, "ref_tone.tests.toml")); err != nil {
+		t.Fatalf("tests file should move with the entry")
+	}
+	if out := gitOut(t, store, "status", "--porcelain"); out != "" {
+		t.Fatalf("everything should be committed:\n%s", out)
+	}
+	if out := gitOut(t, store, "log", "--oneline"); strings.Count(out, "rename ref_style.md") != 1 {
+		t.Fatalf("expected a single rename commit:\n%s", out)
+	}
+	if out := runPea(t, home, "", "get", "ref_pinned"); out != "old: Be terse.\n" {
+		t.Fatalf("pinned include should still resolve: %q", out)
+	}
+	if out := runPea(t, home, "", "rv"); out != "Be terse.\n" {
+		t.Fatalf("alias should follow the rename: %q", out)
+	}
+}
+
+func TestRenameWithoutUpdatingReferences(t *testing.T) {
+	home, _ := peaHome(t, "")
+	store := filepath.Join(home, ".pea", "prompts")
+	runPea(t, home, "Be terse.\n", "add", "noref_style")
+	runPea(t, home, "{{> noref_style}}\n", "add", "noref_main")
+
+	runPea(t, home, "", "mv", "noref_style", "noref_tone", "--no-update-refs")
+	if b, _ := os.ReadFile(filepath.Join(store, "noref_main.md")); string(b) != "{{> noref_style}}\n" {
+		t.Fatalf("--no-update-refs should leave includes alone: %q", b)
+	}
+}
+
+func TestRenameRolledBackWhenCommitFails(t *testing.T) {
+	config := "[aliases]\nrb = \"get rb_style\"\n"
+	home, store := peaHome(t, config)
+	runPea(t, home, "Be terse.\n", "add", "rb_style")
+	runPea(t, home, "{{> rb_style}}\n", "add", "rb_main")
+	writeStoreFile(t, store, ".git/hooks/pre-commit", "#!/bin/sh\necho rejected >&2\nexit 1\n")
+	if err := os.Chmod(filepath.Join(store, ".git", "hooks", "pre-commit"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := peaFails(t, home, "mv", "rb_style", "rb_tone"); !strings.Contains(out, "git commit failed") {
+		t.Fatalf("expected the commit failure to be reported: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(store, "rb_style.md")); err != nil {
+		t.Fatalf("the entry should be back at its old name: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store, "rb_tone.md")); !os.IsNotExist(err) {
+		t.Fatalf("the new name should not exist: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(store, "rb_main.md")); string(b) != "{{> rb_style}}\n" {
+		t.Fatalf("includes should be restored: %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(home, ".pea", "config.toml")); string(b) != config {
+		t.Fatalf("config must not change when the commit fails:\n%s", b)
+	}
+	if out := gitOut(t, store, "status", "--porcelain"); out != "" {
+		t.Fatalf("the store should be left as it was:\n%s", out)
+	}
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPinnedEntriesSortFirst(t *testing.T) {
+	home := t.TempDir()
+	for _, n := range []string{"pin_a", "pin_b", "pin_c"} {
+		runPea(t, home, n+"\n", "add", n)
+	}
+
+	if out := runPea(t, home, "", "pin", "pin_c"); out != "pinned pin_c\n" {
+		t.Fatalf("unexpected pin output: %q", out)
+	}
+	if out := runPea(t, home, "", "ls"); out != "pin_c\npin_a\npin_b\n" {
+		t.Fatalf("pinned entry should be listed first:\n%s", out)
+	}
+	if out := runPea(t, home, "", "ls", "--pinned"); out != "pin_c\n" {
+		t.Fatalf("unexpected --pinned output:\n%s", out)
+	}
+
+	store := filepath.Join(home, ".pea", "prompts")
+	log, _ := exec.Command("git", "-C", store, "log", "-n1", "--format=%s").Output()
+	if strings.TrimSpace(string(log)) != "chore: pin pin_c" {
+		t.Fatalf("pin should be committed, last commit: %s", log)
+	}
+
+	runPea(t, home, "", "unpin", "pin_c")
+	if out := runPea(t, home, "", "ls"); out != "pin_a\npin_b\npin_c\n" {
+		t.Fatalf("unpinned entry should sort by name again:\n%s", out)
+	}
+}
+
+func TestListLongShowsMetadata(t *testing.T) {
+	home := t.TempDir()
+	runPea(t, home, "---\ntags: [work, llm]\ndescription: Code review prompt\n---\nReview the diff carefully.\n", "add", "long_review")
+	runPea(t, home, "short\n", "add", "long_plain")
+
+	out := runPea(t, home, "", "ls", "-l")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "NAME") || !strings.Contains(lines[0], "AUTHOR") {
+		t.Fatalf("unexpected ls -l output:\n%s", out)
+	}
+	var review string
+	for _, l := range lines {
+		if strings.Contains(l, "long_review") {
+			review = l
+		}
+	}
+	author, err := exec.Command("git", "-C", filepath.Join(home, ".pea", "prompts"), "log", "-n1", "--format=%an").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"work,llm", "Code review prompt", "B  ", strings.TrimSpace(string(author))} {
+		if !strings.Contains(review, want) {
+			t.Errorf("ls -l row missing %q: %q", want, review)
+		}
+	}
+
+	if out := runPea(t, home, "", "ls", "--tag", "llm"); out != "long_review\n" {
+		t.Fatalf("unexpected --tag output:\n%s", out)
+	}
+	if out := runPea(t, home, "", "ls", "--sort", "size"); out != "long_review\nlong_plain\n" {
+		t.Fatalf("unexpected --sort size output:\n%s", out)
+	}
+}
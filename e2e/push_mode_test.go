@@ -0,0 +1,111 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bareRemote creates an empty bare repository and returns it with the config
+// line that makes it the store's remote.
+func bareRemote(t *testing.T) (remote, config string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "pea-remote-")
+	if err != nil {
+		t.Fatalf("mkdtemp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	remote = filepath.Join(dir, "remote.git")
+	if out, err := exec.Command("git", "init", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("init bare: %v\n%s", err, out)
+	}
+	return remote, "remote_url = \"" + remote + "\"\n"
+}
+
+func remoteHasCommits(remote string) bool {
+	return exec.Command("git", "--git-dir", remote, "rev-parse", "--verify", "HEAD").Run() == nil
+}
+
+func TestManualPushQueuesUntilSync(t *testing.T) {
+	remote, config := bareRemote(t)
+	home, _ := peaHome(t, config+"push = \"manual\"\n")
+
+	runPea(t, home, "one\n", "add", "manual_one")
+	runPea(t, home, "two\n", "add", "manual_two")
+
+	if remoteHasCommits(remote) {
+		t.Fatalf("manual mode should not push on add")
+	}
+
+	status := runPea(t, home, "", "status")
+	if !strings.Contains(status, "push: manual") {
+		t.Fatalf("status missing push mode:\n%s", status)
+	}
+	if !strings.Contains(status, "unpushed commits: 2") || !strings.Contains(status, "manual_two.md") {
+		t.Fatalf("status should list queued commits:\n%s", status)
+	}
+
+	runPea(t, home, "", "sync")
+
+	if !remoteHasCommits(remote) {
+		t.Fatalf("sync should push queued commits")
+	}
+	if status := runPea(t, home, "", "status"); !strings.Contains(status, "up to date") {
+		t.Fatalf("status should be up to date after sync:\n%s", status)
+	}
+}
+
+func TestBatchedPushFlushesAfterThreshold(t *testing.T) {
+	remote, config := bareRemote(t)
+	home, _ := peaHome(t, config+"push = \"batched\"\npush_batch = 2\n")
+
+	runPea(t, home, "one\n", "add", "batch_one")
+	if remoteHasCommits(remote) {
+		t.Fatalf("batched mode should not push below threshold")
+	}
+
+	runPea(t, home, "two\n", "add", "batch_two")
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !remoteHasCommits(remote) {
+		if time.Now().After(deadline) {
+			t.Fatalf("background push did not reach remote")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestInvalidPushModeRejected(t *testing.T) {
+	_, config := bareRemote(t)
+	home, _ := peaHome(t, config+"push = \"sometimes\"\n")
+
+	c := exec.Command(buildBinary(t), "ls")
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected invalid push mode to fail, got: %s", out)
+	}
+	if !strings.Contains(string(out), "push must be one of") {
+		t.Fatalf("expected push mode error, got: %s", out)
+	}
+}
+
+func TestImmediatePushDoesNotHoldStoreLock(t *testing.T) {
+	remote, config := bareRemote(t)
+	home, store := peaHome(t, config)
+	// The remote refuses pushes made while the store lock is held, so the
+	// entry only arrives if pea released the lock before pushing.
+	hook := "#!/bin/sh\nflock -n '" + filepath.Join(store, ".pea.lock") + "' true || { echo 'store still locked' >&2; exit 1; }\n"
+	if err := os.WriteFile(filepath.Join(remote, "hooks", "pre-receive"), []byte(hook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runPea(t, home, "one\n", "add", "unlocked_push")
+	if !remoteHasCommits(remote) {
+		t.Fatalf("push should run after the store lock is released")
+	}
+}
@@ -0,0 +1,188 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func assertLinkedAndPushed(t *testing.T, store, remote string) {
+	t.Helper()
+	out, err := exec.Command("git", "-C", store, "remote", "get-url", "origin").CombinedOutput()
+	if err != nil {
+		t.Fatalf("get-url: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(string(out)) != remote {
+		t.Fatalf("origin = %q, want %q", strings.TrimSpace(string(out)), remote)
+	}
+	if !remoteHasCommits(remote) {
+		t.Fatalf("remote %s has no commits", remote)
+	}
+}
+
+func TestRemoteCreateBareLocalPath(t *testing.T) {
+	repos := t.TempDir()
+	home, store := peaHome(t, "[remote]\nprovider = \"bare\"\npath = \""+repos+"\"\n")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+
+	out := runPea(t, home, "y\n", "remote", "create", "prompts")
+	if !strings.Contains(out, "created and linked") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	assertLinkedAndPushed(t, store, filepath.Join(repos, "prompts.git"))
+}
+
+// fakeProviderAPI serves a provider's "create repository" endpoint, creating
+// a local bare repository and returning its path as the clone URL.
+func fakeProviderAPI(t *testing.T, path, authHeader, authValue string, respond func(repo string) map[string]any) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	repos := t.TempDir()
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get(authHeader) != authValue {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name, _ := got["name"].(string)
+		repo := filepath.Join(repos, name+".git")
+		if out, err := exec.Command("git", "init", "--bare", "--quiet", repo).CombinedOutput(); err != nil {
+			http.Error(w, string(out), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(respond(repo))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestRemoteCreateGitea(t *testing.T) {
+	var repo string
+	srv, got := fakeProviderAPI(t, "/api/v1/user/repos", "Authorization", "token secret-token", func(r string) map[string]any {
+		repo = r
+		return map[string]any{"ssh_url": r, "clone_url": "https://example.invalid/x.git"}
+	})
+	home, store := peaHome(t, "[remote]\nprovider = \"gitea\"\nurl = \""+srv.URL+"\"\ntoken = \"secret-token\"\n")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+
+	runPea(t, home, "y\n", "remote", "create", "gitea_prompts")
+
+	if (*got)["private"] != true {
+		t.Fatalf("expected private repo request, got %v", *got)
+	}
+	assertLinkedAndPushed(t, store, repo)
+}
+
+func TestRemoteCreateGitLabHTTPSProtocol(t *testing.T) {
+	var repo string
+	srv, got := fakeProviderAPI(t, "/api/v4/projects", "PRIVATE-TOKEN", "env-token", func(r string) map[string]any {
+		repo = r
+		return map[string]any{"ssh_url_to_repo": "git@example.invalid:x.git", "http_url_to_repo": r}
+	})
+	home, store := peaHome(t, "[remote]\nprovider = \"gitlab\"\nurl = \""+srv.URL+"\"\nprotocol = \"https\"\n")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+
+	c := exec.Command(buildBinary(t), "remote", "create", "gitlab_prompts", "--public")
+	c.Env = append(os.Environ(), "HOME="+home, "PEA_REMOTE_TOKEN=env-token")
+	c.Stdin = strings.NewReader("y\n")
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("remote create failed: %v\n%s", err, out)
+	}
+
+	if (*got)["visibility"] != "public" {
+		t.Fatalf("expected public visibility, got %v", *got)
+	}
+	assertLinkedAndPushed(t, store, repo)
+}
+
+func TestRemoteCreateProviderErrorsAreReported(t *testing.T) {
+	srv, _ := fakeProviderAPI(t, "/api/v1/user/repos", "Authorization", "token right", func(r string) map[string]any {
+		return map[string]any{"clone_url": r}
+	})
+	home, _ := peaHome(t, "[remote]\nprovider = \"gitea\"\nurl = \""+srv.URL+"\"\ntoken = \"wrong\"\n")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+
+	c := exec.Command(buildBinary(t), "remote", "create", "denied")
+	c.Env = append(os.Environ(), "HOME="+home)
+	c.Stdin = strings.NewReader("y\n")
+	out, err := c.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected failure with wrong token, got: %s", out)
+	}
+	if !strings.Contains(string(out), "401") {
+		t.Fatalf("expected HTTP status in error, got: %s", out)
+	}
+}
+
+// fakeGH installs a stand-in for the GitHub CLI. 'gh repo create' makes a
+// bare repository under the returned directory and, when setOrigin is true,
+// links and pushes the store like the real --push does.
+func fakeGH(t *testing.T, setOrigin bool) (path, repos string) {
+	t.Helper()
+	binDir := t.TempDir()
+	repos = t.TempDir()
+	link := ""
+	if setOrigin {
+		link = "git remote add origin \"$repo\" && git push --quiet -u origin HEAD\n"
+	}
+	script := "#!/bin/sh\nset -e\nif [ \"$1\" = api ]; then echo tester; exit 0; fi\n" +
+		"repo='" + repos + "'/\"$3\".git\ngit init --bare --quiet \"$repo\"\n" + link
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH"), repos
+}
+
+func TestRemoteCreateGitHubPushesOnce(t *testing.T) {
+	path, repos := fakeGH(t, true)
+	home, store := peaHome(t, "")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+	pushes := filepath.Join(t.TempDir(), "pushes")
+	writeStoreFile(t, store, ".git/hooks/pre-push", "#!/bin/sh\necho push >> '"+pushes+"'\n")
+	if err := os.Chmod(filepath.Join(store, ".git", "hooks", "pre-push"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := exec.Command(buildBinary(t), "remote", "create", "gh_prompts")
+	c.Env = append(os.Environ(), "HOME="+home, path)
+	c.Stdin = strings.NewReader("y\n")
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("remote create failed: %v\n%s", err, out)
+	}
+
+	assertLinkedAndPushed(t, store, filepath.Join(repos, "gh_prompts.git"))
+	if b, _ := os.ReadFile(pushes); string(b) != "push\n" {
+		t.Fatalf("expected gh's push only, got %q", b)
+	}
+}
+
+func TestRemoteCreateGitHubWithoutOriginFails(t *testing.T) {
+	path, _ := fakeGH(t, false)
+	home, store := peaHome(t, "")
+	runPea(t, home, "seed\n", "add", "provider_seed")
+
+	c := exec.Command(buildBinary(t), "remote", "create", "gh_unlinked")
+	c.Env = append(os.Environ(), "HOME="+home, path)
+	c.Stdin = strings.NewReader("y\n")
+	out, err := c.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "its URL is unknown") {
+		t.Fatalf("expected an unknown URL error, got err=%v:\n%s", err, out)
+	}
+	if remote := gitOut(t, store, "remote"); remote != "" {
+		t.Fatalf("no remote should be configured, got %q", remote)
+	}
+}
@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+const runnerConfig = `runner = "upper"
+
+[runners]
+upper = "tr a-z A-Z"
+quote = "sed 's/^/> /'"
+`
+
+func TestRunPipesRenderedEntryToRunner(t *testing.T) {
+	home, _ := peaHome(t, runnerConfig)
+	runPea(t, home, "---\nvars:\n  lang: go\n  topic:\n---\nreview {{lang}} code about {{topic}}\n", "add", "run_review")
+
+	if out := runPea(t, home, "", "run", "run_review", "--var", "topic=errors"); out != "REVIEW GO CODE ABOUT ERRORS\n" {
+		t.Fatalf("unexpected default runner output: %q", out)
+	}
+	if out := runPea(t, home, "", "run", "run_review", "--runner", "quote", "--var", "topic=io", "--var", "lang=ruby"); out != "> review ruby code about io\n" {
+		t.Fatalf("unexpected --runner output: %q", out)
+	}
+	if out := runPea(t, home, "", "run", "run_review", "--var", "topic=io", "--", "cat"); out != "review go code about io\n" {
+		t.Fatalf("unexpected -- command output: %q", out)
+	}
+	if out := runPea(t, home, "", "get", "run_review", "--var", "topic=io"); out != "review go code about io\n" {
+		t.Fatalf("get should render templates too: %q", out)
+	}
+
+	if out := peaFails(t, home, "run", "run_review"); !strings.Contains(out, "missing template variables: topic") {
+		t.Fatalf("expected missing variable error:\n%s", out)
+	}
+	if out := peaFails(t, home, "run", "run_review", "--var", "topic=x", "--runner", "nope"); !strings.Contains(out, "unknown runner \"nope\": use one of quote, upper") {
+		t.Fatalf("expected unknown runner error:\n%s", out)
+	}
+}
+
+func TestRunUsesFrontMatterRunner(t *testing.T) {
+	home, _ := peaHome(t, runnerConfig)
+	runPea(t, home, "---\nrunner: quote\n---\nhello\n", "add", "run_quoted")
+
+	if out := runPea(t, home, "", "run", "run_quoted"); out != "> hello\n" {
+		t.Fatalf("front matter runner should win over the default: %q", out)
+	}
+}
+
+func TestRunWithoutRunnerFails(t *testing.T) {
+	home, _ := peaHome(t, "")
+	runPea(t, home, "hello\n", "add", "run_plain")
+
+	if out := peaFails(t, home, "run", "run_plain"); !strings.Contains(out, "no runner configured") {
+		t.Fatalf("expected no runner error:\n%s", out)
+	}
+	if out := peaFails(t, home, "run", "run_plain", "--", "false"); !strings.Contains(out, "runner failed") {
+		t.Fatalf("expected runner failure to be reported:\n%s", out)
+	}
+}
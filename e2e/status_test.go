@@ -0,0 +1,118 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusListsUncommittedEntriesAndCommitSweepsThem(t *testing.T) {
+	_, config := bareRemote(t)
+	home, store := peaHome(t, config+"push = \"manual\"\n")
+
+	runPea(t, home, "keep\n", "add", "st_modified")
+	runPea(t, home, "gone\n", "add", "st_deleted")
+
+	if err := os.WriteFile(filepath.Join(store, "st_modified.md"), []byte("changed outside\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(store, "st_deleted.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store, "st_untracked.md"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status := runPea(t, home, "", "status")
+	for _, want := range []string{"modified:  st_modified", "deleted:   st_deleted", "untracked: st_untracked", "unpushed commits: 2"} {
+		if !strings.Contains(status, want) {
+			t.Fatalf("status missing %q:\n%s", want, status)
+		}
+	}
+
+	runPea(t, home, "", "commit", "-m", "chore: sweep external edits")
+
+	log, err := exec.Command("git", "-C", store, "log", "-n1", "--name-status", "--format=%s").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v\n%s", err, log)
+	}
+	for _, want := range []string{"chore: sweep external edits", "st_modified.md", "st_deleted.md", "st_untracked.md"} {
+		if !strings.Contains(string(log), want) {
+			t.Fatalf("commit missing %q:\n%s", want, log)
+		}
+	}
+
+	if status := runPea(t, home, "", "status"); !strings.Contains(status, "working tree clean") {
+		t.Fatalf("status should be clean after commit:\n%s", status)
+	}
+	if out := runPea(t, home, "", "commit"); !strings.Contains(out, "nothing to commit") {
+		t.Fatalf("expected nothing to commit, got: %s", out)
+	}
+
+	// The default message counts everything staged, not just entries.
+	if err := os.WriteFile(filepath.Join(store, "st_modified.md"), []byte("again\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store, "st_modified.tests.toml"), []byte("[[case]]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if status := runPea(t, home, "", "status"); !strings.Contains(status, "untracked: st_modified.tests.toml") {
+		t.Fatalf("status should list non-entry files:\n%s", status)
+	}
+	if out := runPea(t, home, "", "commit"); out != "modified:  st_modified\nuntracked: st_modified.tests.toml\n" {
+		t.Fatalf("unexpected commit output: %q", out)
+	}
+	if subject := gitOut(t, store, "log", "-n1", "--format=%s"); subject != "chore: commit 1 changed entry and 1 other file\n" {
+		t.Fatalf("unexpected commit message: %q", subject)
+	}
+}
+
+func TestStatusShowsUnpulledCommitsAfterFetch(t *testing.T) {
+	remote, config := bareRemote(t)
+	home, _ := peaHome(t, config)
+
+	runPea(t, home, "local\n", "add", "st_local")
+
+	// Another machine pushes a commit to the shared remote
+	clone := filepath.Join(home, "clone")
+	if out, err := exec.Command("git", "clone", remote, clone).CombinedOutput(); err != nil {
+		t.Fatalf("clone: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "st_remote.md"), []byte("remote\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "feat: add st_remote.md"}, {"push"}} {
+		if out, err := exec.Command("git", append([]string{"-C", clone}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	status := runPea(t, home, "", "status", "--fetch")
+	if !strings.Contains(status, "unpulled commits: 1") || !strings.Contains(status, "st_remote.md") {
+		t.Fatalf("status should report unpulled commit:\n%s", status)
+	}
+}
+
+func TestStatusReportsRenamesAndUnusualNamesVerbatim(t *testing.T) {
+	home, store := peaHome(t, "")
+
+	runPea(t, home, "old\n", "add", "st_rename_old")
+	if out, err := exec.Command("git", "-C", store, "mv", "st_rename_old.md", "st_rename_new.md").CombinedOutput(); err != nil {
+		t.Fatalf("git mv: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(store, "st café notes.md"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status := runPea(t, home, "", "status")
+	for _, want := range []string{"renamed:   st_rename_new\n", "untracked: st café notes\n"} {
+		if !strings.Contains(status, want) {
+			t.Fatalf("status missing %q:\n%s", want, status)
+		}
+	}
+	if strings.Contains(status, "->") || strings.Contains(status, `"`) {
+		t.Fatalf("status should not show git's rename arrows or quoting:\n%s", status)
+	}
+}
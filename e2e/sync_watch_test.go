@@ -0,0 +1,62 @@
+package e2e
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncWatchCommitsExternalEditsAndPushes(t *testing.T) {
+	bin := buildBinary(t)
+	remote, config := bareRemote(t)
+	home, store := peaHome(t, config+"push = \"manual\"\n")
+
+	// Initialise the store with a first entry
+	runPea(t, home, "seed\n", "add", "watch_seed")
+
+	var out bytes.Buffer
+	daemon := exec.Command(bin, "sync", "--watch", "--interval", "200ms")
+	daemon.Env = append(os.Environ(), "HOME="+home)
+	daemon.Stdout = &out
+	daemon.Stderr = &out
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("start daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = daemon.Process.Kill()
+		_ = daemon.Wait()
+	})
+
+	// Simulate an external editor writing a new entry
+	if err := os.WriteFile(filepath.Join(store, "watch_external.md"), []byte("external\n"), 0o644); err != nil {
+		t.Fatalf("write external entry: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		files, _ := exec.Command("git", "--git-dir", remote, "log", "--name-only", "--format=").CombinedOutput()
+		if strings.Contains(string(files), "watch_external.md") {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+			t.Fatalf("external edit was not committed and pushed; daemon output:\n%s", out.String())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// The daemon must not block regular commands from committing
+	runPea(t, home, "during\n", "add", "watch_during")
+	log, err := exec.Command("git", "-C", store, "log", "--format=%s").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v\n%s", err, log)
+	}
+	if !strings.Contains(string(log), "watch_during.md") {
+		t.Fatalf("add during watch was not committed:\n%s", log)
+	}
+}
@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTagListAndRename(t *testing.T) {
+	home, _ := peaHome(t, "")
+	store := filepath.Join(home, ".pea", "prompts")
+	runPea(t, home, "---\ntags: [Wrok, email]\n---\none\n", "add", "tagged_inline")
+	runPea(t, home, "---\ndescription: two\ntags:\n- wrok\n- work\n---\ntwo\n", "add", "tagged_list")
+	runPea(t, home, "---\ntags: email, misc\n---\nthree\n", "add", "tagged_bare")
+
+	if out := runPea(t, home, "", "tag", "ls"); out != "2  email\n2  Wrok\n1  misc\n1  work\n" {
+		t.Fatalf("unexpected tag ls: %q", out)
+	}
+	if out := runPea(t, home, "", "tag", "ls", "--sort", "name"); out != "2  email\n1  misc\n1  work\n2  Wrok\n" {
+		t.Fatalf("unexpected tag ls --sort name: %q", out)
+	}
+
+	if out := runPea(t, home, "", "tag", "rename", "wrok", "work", "--dry-run"); out != "dry-run: would update tagged_inline.md (tags: work, email)\ndry-run: would update tagged_list.md (tags: work)\n" {
+		t.Fatalf("unexpected dry-run: %q", out)
+	}
+
+	before := commitCount(t, store)
+	if out := runPea(t, home, "", "tag", "rename", "wrok", "work"); out != "tagged_inline\ntagged_list\n" {
+		t.Fatalf("unexpected rename output: %q", out)
+	}
+	if n := commitCount(t, store); n != before+1 {
+		t.Fatalf("expected one commit, got %d", n-before)
+	}
+	want := map[string]string{
+		"tagged_inline.md": "---\ntags: [work, email]\n---\none\n",
+		"tagged_list.md":   "---\ndescription: two\ntags:\n- work\n---\ntwo\n",
+		"tagged_bare.md":   "---\ntags: email, misc\n---\nthree\n",
+	}
+	for file, content := range want {
+		if b, _ := os.ReadFile(filepath.Join(store, file)); string(b) != content {
+			t.Fatalf("%s: got %q, want %q", file, b, content)
+		}
+	}
+
+	runPea(t, home, "", "tag", "add", "tagged_bare", "work", "new")
+	if b, _ := os.ReadFile(filepath.Join(store, "tagged_bare.md")); string(b) != "---\ntags: email, misc, work, new\n---\nthree\n" {
+		t.Fatalf("tag add should keep the bare inline style: %q", b)
+	}
+
+	// With --tag the pattern may be left out: every argument after -- is a tag.
+	runPea(t, home, "", "tag", "add", "--tag", "misc", "--", "misc_seen")
+	if b, _ := os.ReadFile(filepath.Join(store, "tagged_bare.md")); string(b) != "---\ntags: email, misc, work, new, misc_seen\n---\nthree\n" {
+		t.Fatalf("tag add --tag without a pattern: %q", b)
+	}
+	runPea(t, home, "", "tag", "rm", "--tag", "email", "--", "email")
+	if b, _ := os.ReadFile(filepath.Join(store, "tagged_inline.md")); string(b) != "---\ntags: [work]\n---\none\n" {
+		t.Fatalf("tag rm --tag without a pattern: %q", b)
+	}
+	if out := peaFails(t, home, "tag", "add", "new"); !strings.Contains(out, "needs a name or glob") {
+		t.Fatalf("expected missing pattern error:\n%s", out)
+	}
+	if out := peaFails(t, home, "tag", "add", "--", "new"); !strings.Contains(out, "unless --tag or --query") {
+		t.Fatalf("expected -- without a selector to fail:\n%s", out)
+	}
+
+	if out := peaFails(t, home, "tag", "rename", "nope", "other"); !strings.Contains(out, `no entries tagged "nope"`) {
+		t.Fatalf("expected unknown tag error:\n%s", out)
+	}
+}
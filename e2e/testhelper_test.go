@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -96,3 +97,56 @@ func findModuleRoot() (string, error) {
 		dir = parent
 	}
 }
+
+func runPea(t *testing.T, home, stdin string, args ...string) string {
+	t.Helper()
+	c := exec.Command(buildBinary(t), args...)
+	c.Env = append(os.Environ(), "HOME="+home)
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
+	}
+	out, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("pea %v failed: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func peaFails(t *testing.T, home string, args ...string) string {
+	t.Helper()
+	c := exec.Command(buildBinary(t), args...)
+	c.Env = append(os.Environ(), "HOME="+home)
+	out, err := c.CombinedOutput()
+	if err == nil {
+		t.Fatalf("pea %v should fail:\n%s", args, out)
+	}
+	return string(out)
+}
+
+func writeStoreFile(t *testing.T, store, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(store, file), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// peaHome returns a fresh HOME whose ~/.pea/config.toml holds config, and the
+// default store path under it. The directory is removed on a best-effort
+// basis because background pushes may still be writing when a test ends.
+func peaHome(t *testing.T, config string) (home, store string) {
+	t.Helper()
+	home, err := os.MkdirTemp("", "pea-home-")
+	if err != nil {
+		t.Fatalf("mkdtemp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(home) })
+
+	base := filepath.Join(home, ".pea")
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "config.toml"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return home, filepath.Join(base, "prompts")
+}
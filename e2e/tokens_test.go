@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeRankFile installs a tiny tiktoken-style rank table: every single
+// byte plus a few merges that spell "hello" and " w".
+func writeRankFile(t *testing.T, home, encoding string) {
+	t.Helper()
+	dir := filepath.Join(home, ".pea", "tokenizers")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, tok := range []string{"he", "ll", "hell", "hello", " w"} {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), 256+i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, encoding+".tiktoken"), []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetStatsUsesModelEncoding(t *testing.T) {
+	home := t.TempDir()
+	runPea(t, home, "---\nmodel: gpt-4-turbo\n---\nhello world\n", "add", "tok_bpe")
+
+	missing := "cl100k_base.tiktoken not found in " + filepath.Join(home, ".pea", "tokenizers")
+	if out := runPea(t, home, "", "get", "tok_bpe", "--stats"); out != "chars:  12\nwords:  2\ntokens: 2 (approx; "+missing+")\n" {
+		t.Fatalf("without a rank file the approximation should be used and say so:\n%s", out)
+	}
+	if out := runPea(t, home, "", "ls", "-l"); !strings.Contains(out, "  ~2  ") || !strings.Contains(out, "note: token counts marked ~ are approximate: "+missing) {
+		t.Fatalf("ls -l should mark approximate token counts:\n%s", out)
+	}
+
+	writeRankFile(t, home, "cl100k_base")
+	// "hello" | " w" "o" "r" "l" "d" | "\n"
+	if out := runPea(t, home, "", "get", "tok_bpe", "--stats"); out != "chars:  12\nwords:  2\ntokens: 7 (cl100k_base)\n" {
+		t.Fatalf("unexpected BPE stats:\n%s", out)
+	}
+
+	if out := runPea(t, home, "", "ls", "-l"); !strings.Contains(out, "TOKENS") || !strings.Contains(out, "  7  ") {
+		t.Fatalf("ls -l should show token counts:\n%s", out)
+	}
+}
+
+func TestMaxTokensEnforced(t *testing.T) {
+	home, _ := peaHome(t, "tokenizer = \"approx\"\n")
+	runPea(t, home, "one two three\n", "add", "tok_part")
+
+	over := "---\nmax_tokens: 3\n---\n{{> tok_part}} four five\n"
+	add := exec.Command(buildBinary(t), "add", "tok_budget")
+	add.Env = append(os.Environ(), "HOME="+home)
+	add.Stdin = strings.NewReader(over)
+	out, err := add.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "add failed: entry is 5 tokens (approx), over max_tokens 3") {
+		t.Fatalf("expected budget error, got err=%v:\n%s", err, out)
+	}
+	store := filepath.Join(home, ".pea", "prompts")
+	if _, err := os.Stat(filepath.Join(store, "tok_budget.md")); !os.IsNotExist(err) {
+		t.Fatalf("over-budget entry should not be written")
+	}
+
+	writeStoreFile(t, store, "tok_budget.md", over)
+	if out := peaFails(t, home, "lint", "tok_budget"); !strings.Contains(out, "error: entry is 5 tokens (approx), over max_tokens 3 [tokens]") {
+		t.Fatalf("lint should enforce max_tokens:\n%s", out)
+	}
+	// A collection is budgeted by its rendered members, not its member list.
+	runPea(t, home, "a b c d e f g h i j\n", "add", "tok_long")
+	writeStoreFile(t, store, "tok_bundle.md", "---\ntype: collection\nmax_tokens: 5\n---\n- tok_long\n")
+	if out := peaFails(t, home, "lint", "tok_bundle"); !strings.Contains(out, "over max_tokens 5") {
+		t.Fatalf("collection budget should count rendered members:\n%s", out)
+	}
+}
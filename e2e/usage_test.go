@@ -0,0 +1,90 @@
+package e2e
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUsageOrdersListingsAndTop(t *testing.T) {
+	home := t.TempDir()
+	for _, n := range []string{"use_a", "use_b", "use_c"} {
+		runPea(t, home, n+"\n", "add", n)
+	}
+	runPea(t, home, "", "get", "use_b")
+	runPea(t, home, "", "cp", "use_b")
+	time.Sleep(1100 * time.Millisecond)
+	runPea(t, home, "", "use_c")
+
+	if out := runPea(t, home, "", "ls", "--sort", "frequent"); out != "use_b\nuse_c\nuse_a\n" {
+		t.Fatalf("unexpected frequent order:\n%s", out)
+	}
+	if out := runPea(t, home, "", "ls", "--sort", "recent"); out != "use_c\nuse_b\nuse_a\n" {
+		t.Fatalf("unexpected recent order:\n%s", out)
+	}
+
+	top := strings.Split(strings.TrimSpace(runPea(t, home, "", "top")), "\n")
+	if len(top) != 2 || !strings.HasPrefix(top[0], "2 ") || !strings.HasSuffix(top[0], "use_b") || !strings.HasSuffix(top[1], "use_c") {
+		t.Fatalf("unexpected top output:\n%s", strings.Join(top, "\n"))
+	}
+
+	out := runPea(t, home, "", "__complete", "get", "use_")
+	if !strings.HasPrefix(out, "use_b\nuse_c\nuse_a\n") {
+		t.Fatalf("completion should list frequent entries first:\n%s", out)
+	}
+
+	store := filepath.Join(home, ".pea", "prompts")
+	if st, _ := exec.Command("git", "-C", store, "status", "--porcelain").Output(); len(st) != 0 {
+		t.Fatalf("usage data must stay out of the store:\n%s", st)
+	}
+}
+
+func TestUsageTrackingCanBeDisabled(t *testing.T) {
+	home, _ := peaHome(t, "track_usage = false\n")
+	base := filepath.Join(home, ".pea")
+
+	runPea(t, home, "x\n", "add", "untracked_use")
+	runPea(t, home, "", "get", "untracked_use")
+
+	if _, err := os.Stat(filepath.Join(base, "usage.tsv")); !os.IsNotExist(err) {
+		t.Fatalf("usage should not be recorded when track_usage = false")
+	}
+	if out := runPea(t, home, "", "top"); !strings.Contains(out, "no usage recorded yet") {
+		t.Fatalf("unexpected top output:\n%s", out)
+	}
+}
+
+func TestUsageCompactionKeepsConcurrentEvents(t *testing.T) {
+	home, store := peaHome(t, "")
+	runPea(t, home, "x\n", "add", "busy_use")
+
+	// A log just over the size limit makes the next event compact it.
+	old := "0\tget\t" + store + "\told_use" + strings.Repeat("_", 200) + "\n"
+	writeStoreFile(t, filepath.Join(home, ".pea"), "usage.tsv", strings.Repeat(old, 6000))
+
+	const gets = 8
+	var wg sync.WaitGroup
+	for i := 0; i < gets; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runPea(t, home, "", "get", "busy_use")
+		}()
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(filepath.Join(home, ".pea", "usage.tsv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "\tbusy_use\n"); n != gets {
+		t.Fatalf("expected %d recorded gets after compaction, found %d", gets, n)
+	}
+	if n := strings.Count(string(b), "\n"); n >= 6000 {
+		t.Fatalf("usage log was not compacted: %d events", n)
+	}
+}
@@ -0,0 +1,92 @@
+package e2e
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while a process writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestGetWatchReRendersOnChange(t *testing.T) {
+	writeEntry(t, "watch_part", "Be terse.\n")
+	writeEntry(t, "watch_main", "---\nvars:\n  lang: go\n---\nReview {{lang}}.\n{{> watch_part}}\n")
+
+	// Output is piped, so like get the watch must leave the clipboard alone.
+	if err := os.WriteFile(os.Getenv("PEA_FAKE_CLIP_FILE"), []byte("untouched"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr syncBuffer
+	c := exec.Command(buildBinary(t), "get", "watch_main", "--watch", "--var", "lang=rust")
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Process.Kill() }()
+
+	waitFor(t, "watch to start", func() bool { return strings.Contains(stderr.String(), "watching watch_main") })
+	if got := stdout.String(); got != "Review rust.\nBe terse.\n" {
+		t.Fatalf("unexpected initial render: %q", got)
+	}
+
+	// Changing an included entry re-renders the includer.
+	writeEntry(t, "watch_part", "Be very terse.\n")
+	waitFor(t, "include change", func() bool { return strings.HasSuffix(stdout.String(), "Review rust.\nBe very terse.\n") })
+
+	writeEntry(t, "watch_main", "Check {{lang}}.\n{{> watch_part}}\n")
+	waitFor(t, "entry change", func() bool { return strings.HasSuffix(stdout.String(), "Check rust.\nBe very terse.\n") })
+
+	// Unrelated entries don't trigger a render.
+	renders := strings.Count(stderr.String(), "updated watch_main")
+	writeEntry(t, "watch_unrelated", "noise\n")
+	time.Sleep(400 * time.Millisecond)
+	if n := strings.Count(stderr.String(), "updated watch_main"); n != renders {
+		t.Fatalf("unrelated change re-rendered:\n%s", stderr.String())
+	}
+
+	if err := c.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Wait(); err != nil {
+		t.Fatalf("watch should exit cleanly on interrupt: %v\n%s", err, stderr.String())
+	}
+	if !strings.HasSuffix(stdout.String(), "Check rust.\nBe very terse.\n") {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+	if got := readFakeClipboard(t); got != "untouched" {
+		t.Fatalf("piped watch should not copy to the clipboard: %q", got)
+	}
+}
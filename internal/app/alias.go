@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxAliasDepth bounds how many aliases may expand into one another.
+const maxAliasDepth = 10
+
+// ExpandAlias replaces a leading alias in args with its definition. Aliases
+// may refer to other aliases; names for which isCommand reports true are
+// never treated as aliases, so built-in commands cannot be shadowed.
+func ExpandAlias(args []string, aliases map[string]string, isCommand func(string) bool) ([]string, error) {
+	var seen []string
+	for len(args) > 0 && !isCommand(args[0]) {
+		def, ok := aliases[args[0]]
+		if !ok {
+			break
+		}
+		for _, s := range seen {
+			if s == args[0] {
+				return nil, fmt.Errorf("alias cycle: %s -> %s", strings.Join(seen, " -> "), args[0])
+			}
+		}
+		seen = append(seen, args[0])
+		if len(seen) > maxAliasDepth {
+			return nil, fmt.Errorf("alias %q expands too deeply", seen[0])
+		}
+		words, err := SplitArgs(def)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: %w", args[0], err)
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("alias %q is empty", args[0])
+		}
+		args = append(words, args[1:]...)
+	}
+	return args, nil
+}
+
+// SplitArgs splits s into words like a POSIX shell would, honouring single
+// and double quotes and backslash escapes. No expansion is performed.
+func SplitArgs(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
+
+// JoinArgs is the inverse of SplitArgs: it joins words with spaces,
+// single-quoting those that contain spaces, quotes or backslashes.
+func JoinArgs(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if w != "" && !strings.ContainsAny(w, " \t\n'\"\\") {
+			quoted[i] = w
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(w, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CollectionType is the front matter 'type:' of entries that bundle other
+// entries instead of holding text.
+const CollectionType = "collection"
+
+// defaultSeparator goes between collection members: a blank line.
+const defaultSeparator = "\n"
+
+// CollectionMember is an entry listed in a collection, with the template
+// variables to render it with.
+type CollectionMember struct {
+	Name string
+	Vars map[string]string
+	Line int // 1-based line in the collection body
+}
+
+// IsCollection reports whether an entry file is a collection.
+func IsCollection(file []byte) bool {
+	t, _ := FrontMatterValue(file, "type")
+	return t == CollectionType
+}
+
+// ParseCollection reads the members of a collection from its body, one per
+// line and followed by optional name=value variables:
+//
+//	persona
+//	task lang=go topic="error handling"
+//	output_format
+//
+// Lines may be written as "- " list items. Blank lines and lines starting
+// with '#' are ignored.
+func ParseCollection(body []byte) ([]CollectionMember, error) {
+	var members []CollectionMember
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "- "))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words, err := SplitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		m := CollectionMember{Name: words[0], Line: n}
+		for _, w := range words[1:] {
+			k, v, ok := strings.Cut(w, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("line %d: invalid variable %q: use name=value", n, w)
+			}
+			if m.Vars == nil {
+				m.Vars = map[string]string{}
+			}
+			m.Vars[k] = v
+		}
+		members = append(members, m)
+	}
+	return members, sc.Err()
+}
+
+// collectionSeparator returns the 'separator:' of a collection. Double
+// quoted values may use escapes such as "\n---\n".
+func collectionSeparator(file []byte) string {
+	lines, _ := frontMatterLines(file)
+	for _, line := range lines {
+		if v, ok := strings.CutPrefix(line, "separator:"); ok {
+			v = strings.TrimSpace(v)
+			if s, err := strconv.Unquote(v); err == nil && strings.HasPrefix(v, `"`) {
+				return s
+			}
+			return unquote(v)
+		}
+	}
+	return defaultSeparator
+}
+
+// renderCollection renders each member of a collection in order and joins
+// them with the separator. Variables apply from lowest to highest priority:
+// the collection's declared defaults, the member's own, then vars. Members
+// without 'vars:' render unchanged; the others only get the variables they
+// declare or use. Nested collections get them all.
+func renderCollection(store, rev string, file []byte, vars map[string]string, stack []string) ([]byte, error) {
+	name, _, _ := strings.Cut(stack[len(stack)-1], "@")
+	members, err := ParseCollection(StripFrontMatter(file))
+	if err != nil {
+		return nil, fmt.Errorf("collection %s: %w", name, err)
+	}
+	var out bytes.Buffer
+	sep := collectionSeparator(file)
+	for i, m := range members {
+		member, err := NormalizeName(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		key := includeKey(member, rev)
+		for _, k := range stack {
+			if k == key {
+				return nil, fmt.Errorf("collection cycle: %s -> %s", strings.Join(stack, " -> "), key)
+			}
+		}
+		memberFile, err := ReadEntryFileAt(store, member, rev)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "not found:") {
+				return nil, fmt.Errorf("collection %s: %w", name, err)
+			}
+			return nil, err
+		}
+		values := map[string]string{}
+		for _, v := range DeclaredVars(file) {
+			if v.HasDefault {
+				values[v.Name] = v.Default
+			}
+		}
+		for k, v := range m.Vars {
+			values[k] = v
+		}
+		for k, v := range vars {
+			values[k] = v
+		}
+		if !IsCollection(memberFile) {
+			values = memberValues(memberFile, values)
+		}
+		b, err := renderEntry(store, member, rev, values, append(stack, key))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString(sep)
+		}
+		out.Write(b)
+		if len(b) > 0 && b[len(b)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// memberValues returns the values a plain entry file can use: none without
+// a 'vars:' declaration, otherwise those it declares or references.
+func memberValues(file []byte, values map[string]string) map[string]string {
+	decl := DeclaredVars(file)
+	if len(decl) == 0 {
+		return nil
+	}
+	names := TemplateVars(StripFrontMatter(file))
+	for _, v := range decl {
+		names = append(names, v.Name)
+	}
+	out := map[string]string{}
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			out[name] = value
+		}
+	}
+	return out
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -49,6 +51,49 @@ store_dir = "` + defaultStore + `"
 
 # Remote git URL for synchronization (typically set via 'pea remote')
 # remote_url = ""
+
+# When to push commits to the remote: "immediate", "batched" or "manual"
+# push = "immediate"
+
+# Number of unpushed commits that triggers a background push in batched mode
+# push_batch = 5
+
+# Clipboard backend: "auto" (detect), "native", "osc52", "wl-copy", "xclip", "xsel" or "tmux"
+# clipboard = "auto"
+
+# How long entries tagged 'secret' or marked 'sensitive: true' stay on the clipboard ("0" disables)
+# clipboard_clear = "45s"
+
+# Record get, cp, run and ask events in ~/.pea/usage.tsv (never committed) for 'pea top' and 'pea ls --sort'
+# track_usage = true
+
+# Tokenizer for token counts: "auto" (from the model), "approx", "cl100k_base" or "o200k_base".
+# BPE encodings read ~/.pea/tokenizers/<encoding>.tiktoken and fall back to "approx" without it.
+# tokenizer = "auto"
+
+# Commands 'pea run' pipes a rendered entry into; entries may pick one with 'runner:' front matter
+# runner = "llm"
+# [runners]
+# llm = "llm -m gpt-4o-mini"
+# ollama = "ollama run llama3"
+
+# OpenAI-compatible endpoint for 'pea ask' (llama.cpp, Ollama and vLLM serve one too)
+# [llm]
+# endpoint = "https://api.openai.com/v1"   # or PEA_LLM_ENDPOINT, e.g. "http://localhost:8080/v1"
+# api_key = ""                              # or PEA_LLM_API_KEY / OPENAI_API_KEY
+# model = "gpt-4o-mini"                     # entries may override with 'model:' front matter
+
+# Provider used by 'pea remote create': "github" (gh CLI), "gitlab", "gitea" or "bare"
+# [remote]
+# provider = "gitlab"
+# url = "https://gitlab.com"      # API base URL for gitlab/gitea
+# token = ""                      # or PEA_REMOTE_TOKEN, GITLAB_TOKEN, GITEA_TOKEN
+# protocol = "ssh"                # clone URL to configure: "ssh" or "https"
+# path = "ssh://git@host/srv/git" # parent directory for bare repositories
+
+# Command aliases: 'pea r notes' runs 'pea get --raw notes'
+# [aliases]
+# r = "get --raw"
 `
 		if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
 			return "", fmt.Errorf("failed to write default config %s: %w", cfgPath, err)
@@ -65,10 +110,39 @@ store_dir = "` + defaultStore + `"
 }
 
 type Config struct {
-	StoreDir  string `toml:"store_dir,omitempty"`
-	RemoteURL string `toml:"remote_url,omitempty"`
-	Git       *bool  `toml:"git,omitempty"`
-	Editor    string `toml:"editor,omitempty"`
+	StoreDir  string       `toml:"store_dir,omitempty"`
+	RemoteURL string       `toml:"remote_url,omitempty"`
+	Git       *bool        `toml:"git,omitempty"`
+	Editor    string       `toml:"editor,omitempty"`
+	Push      string       `toml:"push,omitempty"`
+	PushBatch int          `toml:"push_batch,omitempty"`
+	Remote    RemoteConfig `toml:"remote,omitempty"`
+	Clipboard string       `toml:"clipboard,omitempty"`
+	// ClipboardClear is how long sensitive entries stay on the clipboard, as a
+	// Go duration such as "45s"; "0" disables clearing.
+	ClipboardClear string `toml:"clipboard_clear,omitempty"`
+	// Aliases map a name to the arguments it expands to, e.g.
+	// r = "get --rev HEAD~1".
+	Aliases map[string]string `toml:"aliases,omitempty"`
+	// TrackUsage records get, cp, run and ask events under ~/.pea for
+	// 'pea top' and 'pea ls --sort'; defaults to true.
+	TrackUsage *bool `toml:"track_usage,omitempty"`
+	// Runner names the entry in Runners that 'pea run' uses by default.
+	Runner  string            `toml:"runner,omitempty"`
+	Runners map[string]string `toml:"runners,omitempty"`
+	LLM     LLMConfig         `toml:"llm,omitempty"`
+	// Tokenizer forces the tokenizer used for token counts: "approx" or an
+	// encoding such as "cl100k_base". Empty or "auto" follows the model.
+	Tokenizer string `toml:"tokenizer,omitempty"`
+}
+
+// ReadConfig decodes the config file at its default location. A missing or
+// invalid file yields the zero Config; EnsureStore reports invalid files.
+func ReadConfig() Config {
+	var conf Config
+	base, _ := DefaultPaths()
+	_, _ = toml.DecodeFile(filepath.Join(base, "config.toml"), &conf)
+	return conf
 }
 
 func GetEditorConfig() string {
@@ -100,6 +174,36 @@ func GetEditorConfig() string {
 	return ""
 }
 
+// GetClipboardConfig returns the configured clipboard provider name, or ""
+// to use the platform default. PEA_CLIPBOARD overrides the config file.
+func GetClipboardConfig() string {
+	if v := os.Getenv("PEA_CLIPBOARD"); v != "" {
+		return v
+	}
+	return ReadConfig().Clipboard
+}
+
+// DefaultClipboardClear is how long sensitive entries stay on the clipboard
+// unless configured otherwise.
+const DefaultClipboardClear = 45 * time.Second
+
+// GetClipboardClearConfig returns how long sensitive entries may stay on the
+// clipboard; zero disables clearing. PEA_CLIPBOARD_CLEAR overrides the config.
+func GetClipboardClearConfig() (time.Duration, error) {
+	v := os.Getenv("PEA_CLIPBOARD_CLEAR")
+	if v == "" {
+		v = ReadConfig().ClipboardClear
+	}
+	if v == "" {
+		return DefaultClipboardClear, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid clipboard_clear %q: use a duration such as \"45s\"", v)
+	}
+	return d, nil
+}
+
 func loadConfig(cfgPath, defaultStore string) (string, string, bool, string, error) {
 	var conf Config
 
@@ -118,6 +222,13 @@ func loadConfig(cfgPath, defaultStore string) (string, string, bool, string, err
 
 	remote := conf.RemoteURL
 
+	if err := validatePushMode(conf.Push); err != nil {
+		return "", "", false, "", fmt.Errorf("invalid config %s: %w", cfgPath, err)
+	}
+	if conf.PushBatch < 0 {
+		return "", "", false, "", fmt.Errorf("invalid config %s: push_batch must not be negative, got %d", cfgPath, conf.PushBatch)
+	}
+
 	// Default to true if not specified
 	enableGit := true
 	if conf.Git != nil {
@@ -129,16 +240,12 @@ func loadConfig(cfgPath, defaultStore string) (string, string, bool, string, err
 
 func SetGitRemote(store, remote string) error {
 	// Try to set-url first (if it exists)
-	cmd := exec.Command("git", "remote", "set-url", "origin", remote)
-	cmd.Dir = store
-	if err := cmd.Run(); err == nil {
+	if _, err := runGit(store, "remote", "set-url", "origin", remote); err == nil {
 		return nil
 	}
 
 	// If set-url failed, try adding it
-	add := exec.Command("git", "remote", "add", "origin", remote)
-	add.Dir = store
-	if out, err := add.CombinedOutput(); err != nil {
+	if out, err := runGit(store, "remote", "add", "origin", remote); err != nil {
 		return fmt.Errorf("git remote add/set-url failed: %v: %s", err, string(out))
 	}
 	return nil
@@ -154,6 +261,12 @@ func prepareStore(store, source, remote string, enableGit bool) (string, error)
 	}
 
 	if enableGit {
+		// Serialize initialization so concurrent first runs don't race on git init.
+		unlock, err := LockStore(store)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
 		if err := ensureGitRepo(store, remote); err != nil {
 			return "", err
 		}
@@ -169,6 +282,9 @@ func ensureGitRepo(store, remote string) error {
 	}
 
 	if _, err := os.Stat(filepath.Join(store, ".git")); err == nil {
+		if err := excludeLockFile(store); err != nil {
+			return err
+		}
 		// configure remote if provided and not set
 		if remote != "" {
 			if err := setRemoteIfMissing(store, remote); err != nil {
@@ -185,8 +301,9 @@ func ensureGitRepo(store, remote string) error {
 	}
 
 	// Check if user.name/email are configured globally or locally
-	hasName := exec.Command("git", "config", "user.name").Run() == nil
-	hasEmail := exec.Command("git", "config", "user.email").Run() == nil
+	_, nameErr := runGit(store, "config", "user.name")
+	_, emailErr := runGit(store, "config", "user.email")
+	hasName, hasEmail := nameErr == nil, emailErr == nil
 
 	if !hasName {
 		cmds = append(cmds, []string{"config", "user.name", "pea"})
@@ -196,13 +313,15 @@ func ensureGitRepo(store, remote string) error {
 	}
 
 	for _, args := range cmds {
-		c := exec.Command("git", args...)
-		c.Dir = store
-		if out, err := c.CombinedOutput(); err != nil {
+		if out, err := runGit(store, args...); err != nil {
 			return fmt.Errorf("git %v failed: %v: %s", args, err, string(out))
 		}
 	}
 
+	if err := excludeLockFile(store); err != nil {
+		return err
+	}
+
 	if remote != "" {
 		if err := setRemoteIfMissing(store, remote); err != nil {
 			return err
@@ -212,15 +331,36 @@ func ensureGitRepo(store, remote string) error {
 	return nil
 }
 
+// excludeLockFile keeps the store lock file out of git via .git/info/exclude.
+func excludeLockFile(store string) error {
+	p := filepath.Join(store, ".git", "info", "exclude")
+	b, err := os.ReadFile(p)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == lockFileName {
+			return nil
+		}
+	}
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	b = append(b, lockFileName+"\n"...)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(p), err)
+	}
+	if err := WriteFileAtomic(p, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p, err)
+	}
+	return nil
+}
+
 func setRemoteIfMissing(store, remote string) error {
-	remoteCmd := exec.Command("git", "remote", "get-url", "origin")
-	remoteCmd.Dir = store
-	if err := remoteCmd.Run(); err == nil {
+	if _, err := runGit(store, "remote", "get-url", "origin"); err == nil {
 		return nil
 	}
-	add := exec.Command("git", "remote", "add", "origin", remote)
-	add.Dir = store
-	if out, err := add.CombinedOutput(); err != nil {
+	if out, err := runGit(store, "remote", "add", "origin", remote); err != nil {
 		return fmt.Errorf("git remote add failed: %v: %s", err, string(out))
 	}
 	return nil
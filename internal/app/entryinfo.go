@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EntryInfo is the metadata shown by 'pea ls -l'.
+type EntryInfo struct {
+	Name        string
+	File        string
+	Tags        []string
+	Description string
+	Size        int64
+	Tokens      int // of the content with includes expanded; detailed only
+	// TokenFallback names the encoding Tokens approximates because its
+	// rank file is not installed.
+	TokenFallback string
+	Modified      time.Time
+	Author        string // author of the last commit touching the entry
+	Pinned        bool
+	Collection    bool
+}
+
+// ListEntryInfo returns metadata for every entry in the store. Commit
+// authors and token counts are only computed when detailed is set.
+func ListEntryInfo(store string, detailed bool) ([]EntryInfo, error) {
+	names, err := ListEntries(store)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := LoadPins(store)
+	if err != nil {
+		return nil, err
+	}
+	var authors map[string]string
+	if detailed && hasGit(store) {
+		authors = lastCommitAuthors(store)
+	}
+	var picker TokenizerPicker
+	if detailed {
+		picker = NewTokenizerPicker()
+	}
+
+	infos := make([]EntryInfo, 0, len(names))
+	for _, name := range names {
+		path, ext, err := ExistingEntryPath(store, name)
+		if err != nil {
+			return nil, err
+		}
+		st, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		desc, _ := FrontMatterValue(b, "description")
+		tokens, fallback := 0, ""
+		collection := IsCollection(b)
+		if detailed {
+			var content []byte
+			if collection {
+				content, err = RenderEntry(store, name, "", nil)
+			} else {
+				content, err = ReadEntry(store, name, "")
+			}
+			if err != nil {
+				content = StripFrontMatter(b)
+			}
+			tok := picker.For(b)
+			tokens, fallback = tok.Count(string(content)), ApproxFallback(tok)
+		}
+		infos = append(infos, EntryInfo{
+			Name:          name,
+			File:          name + ext,
+			Tags:          parseTags(b),
+			Description:   desc,
+			Size:          st.Size(),
+			Tokens:        tokens,
+			TokenFallback: fallback,
+			Modified:      st.ModTime(),
+			Author:        authors[name+ext],
+			Pinned:        pins[name],
+			Collection:    collection,
+		})
+	}
+	return infos, nil
+}
+
+// lastCommitAuthors maps each file in the store to the author of the most
+// recent commit that touched it, using a single pass over the history.
+func lastCommitAuthors(store string) map[string]string {
+	authors := make(map[string]string)
+	out, err := runGit(store, "log", "--format=%x00%an", "--name-only")
+	if err != nil {
+		return authors
+	}
+	author := ""
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "\x00") {
+			author = line[1:]
+			continue
+		}
+		if line != "" {
+			if _, ok := authors[line]; !ok {
+				authors[line] = author
+			}
+		}
+	}
+	return authors
+}
+
+// Additional orderings accepted by SortEntryInfo.
+const (
+	SortModified = "modified"
+	SortSize     = "size"
+)
+
+// SortEntryInfo orders infos with pinned entries first, then by name,
+// recent or frequent use, last modification or size (largest first).
+func SortEntryInfo(infos []EntryInfo, by string, usage map[string]UsageStats) error {
+	names := make([]string, len(infos))
+	for i, e := range infos {
+		names[i] = e.Name
+	}
+	switch by {
+	case SortModified, SortSize:
+	default:
+		if err := SortEntries(names, by, usage); err != nil {
+			return fmt.Errorf("invalid sort %q: use name, recent, frequent, modified or size", by)
+		}
+	}
+	rank := make(map[string]int, len(names))
+	for i, n := range names {
+		rank[n] = i
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		switch by {
+		case SortModified:
+			if !a.Modified.Equal(b.Modified) {
+				return a.Modified.After(b.Modified)
+			}
+			return a.Name < b.Name
+		case SortSize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+			return a.Name < b.Name
+		}
+		return rank[a.Name] < rank[b.Name]
+	})
+	return nil
+}
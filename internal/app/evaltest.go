@@ -0,0 +1,114 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// testsFileSuffix names the file holding an entry's test cases, next to the
+// entry itself: review.md is tested by review.tests.toml.
+const testsFileSuffix = ".tests.toml"
+
+// TestSuite is the content of an entry's tests file.
+type TestSuite struct {
+	// Runner names a runner from the [runners] config table to send the
+	// rendered entry to; it overrides the entry's 'runner:' front matter.
+	Runner string     `toml:"runner"`
+	Cases  []TestCase `toml:"case"`
+}
+
+// TestCase renders an entry with Vars, runs it and checks the output.
+type TestCase struct {
+	Name        string            `toml:"name"`
+	Vars        map[string]string `toml:"vars"`
+	Contains    []string          `toml:"contains"`
+	NotContains []string          `toml:"not_contains"`
+	Regex       []string          `toml:"regex"`
+	JSONSchema  string            `toml:"json_schema"`
+}
+
+// TestsFile returns the path of the tests file for an entry.
+func TestsFile(store, name string) string {
+	return filepath.Join(store, name+testsFileSuffix)
+}
+
+// LoadTests reads the test cases for an entry.
+func LoadTests(store, name string) (TestSuite, error) {
+	var suite TestSuite
+	p := TestsFile(store, name)
+	if _, err := toml.DecodeFile(p, &suite); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return suite, fmt.Errorf("no tests for %s: create %s", name, filepath.Base(p))
+		}
+		return suite, fmt.Errorf("invalid tests file %s: %w", filepath.Base(p), err)
+	}
+	if len(suite.Cases) == 0 {
+		return suite, fmt.Errorf("no [[case]] tables in %s", filepath.Base(p))
+	}
+	for i := range suite.Cases {
+		if suite.Cases[i].Name == "" {
+			suite.Cases[i].Name = fmt.Sprintf("case %d", i+1)
+		}
+	}
+	return suite, nil
+}
+
+// EntriesWithTests lists the entries that have a tests file.
+func EntriesWithTests(store string) ([]string, error) {
+	files, err := os.ReadDir(store)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), testsFileSuffix)
+		if !ok || f.IsDir() {
+			continue
+		}
+		if _, _, err := ExistingEntryPath(store, name); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CheckOutput evaluates the assertions of tc against output and returns a
+// description of each failed assertion.
+func (tc TestCase) CheckOutput(output string) ([]string, error) {
+	var failures []string
+	for _, s := range tc.Contains {
+		if !strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("expected output to contain %q", s))
+		}
+	}
+	for _, s := range tc.NotContains {
+		if strings.Contains(output, s) {
+			failures = append(failures, fmt.Sprintf("expected output not to contain %q", s))
+		}
+	}
+	for _, expr := range tc.Regex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+		}
+		if !re.MatchString(output) {
+			failures = append(failures, fmt.Sprintf("expected output to match /%s/", expr))
+		}
+	}
+	if tc.JSONSchema != "" {
+		problems, err := ValidateJSONSchema([]byte(tc.JSONSchema), []byte(output))
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, problems...)
+	}
+	return failures, nil
+}
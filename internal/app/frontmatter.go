@@ -0,0 +1,54 @@
+package app
+
+import (
+	"strings"
+)
+
+// frontMatterLines returns the lines between the opening and closing '---'
+// delimiters, and whether a closed front matter block was found.
+func frontMatterLines(b []byte) ([]string, bool) {
+	lines := strings.Split(string(b), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return lines[1:i], true
+		}
+	}
+	return nil, false
+}
+
+// FrontMatterValue returns the scalar value of a top-level key in the
+// entry's YAML front matter, with surrounding quotes removed.
+func FrontMatterValue(b []byte, key string) (string, bool) {
+	lines, ok := frontMatterLines(b)
+	if !ok {
+		return "", false
+	}
+	prefix := key + ":"
+	for _, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		return unquote(value), true
+	}
+	return "", false
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' && v[len(v)-1] == '"' || v[0] == '\'' && v[len(v)-1] == '\'') {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// IsSensitive reports whether an entry is marked 'sensitive: true' or
+// tagged 'secret' in its front matter.
+func IsSensitive(b []byte) bool {
+	if v, ok := FrontMatterValue(b, "sensitive"); ok && strings.EqualFold(v, "true") {
+		return true
+	}
+	return HasAllTags(parseTags(b), []string{"secret"})
+}
@@ -7,13 +7,50 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// gitLockRetries and gitLockBackoff control how long git commands are
+// retried when another git process holds the repository's index.lock.
+const (
+	gitLockRetries = 6
+	gitLockBackoff = 50 * time.Millisecond
+)
+
+// runGit runs git in the store and returns its combined output. Commands
+// failing because of index.lock contention, e.g. with a git process started
+// outside pea, are retried with exponential backoff.
+func runGit(store string, args ...string) ([]byte, error) {
+	backoff := gitLockBackoff
+	for attempt := 0; ; attempt++ {
+		c := exec.Command("git", args...)
+		c.Dir = store
+		c.Env = lockEnv(store)
+		out, err := c.CombinedOutput()
+		if err == nil || attempt == gitLockRetries || !strings.Contains(string(out), "index.lock") {
+			return out, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
 func hasGit(store string) bool {
 	_, err := os.Stat(filepath.Join(store, ".git"))
 	return err == nil
 }
 
+// GitEnabled reports whether the store is a git repository.
+func GitEnabled(store string) bool {
+	return hasGit(store)
+}
+
+// IsTracked reports whether path, relative to the store, is known to git.
+func IsTracked(store, path string) bool {
+	_, err := runGit(store, "ls-files", "--error-unmatch", "--", path)
+	return err == nil
+}
+
 // GitAddAndCommit attempts to stage the provided paths and commit with commitMsg.
 // It logs failures to stderr but does not block the calling command.
 func GitAddAndCommit(store string, paths []string, commitMsg string, stderr io.Writer) {
@@ -23,21 +60,33 @@ func GitAddAndCommit(store string, paths []string, commitMsg string, stderr io.W
 	if stderr == nil {
 		stderr = io.Discard
 	}
+	if stageAndCommit(store, append([]string{"add"}, paths...), commitMsg, stderr) {
+		PushAfterCommit(store, stderr)
+	}
+}
 
-	addArgs := append([]string{"add"}, paths...)
-	add := exec.Command("git", addArgs...)
-	add.Dir = store
-	if out, err := add.CombinedOutput(); err != nil {
-		fmt.Fprintf(stderr, "warning: git add failed: %v: %s\n", err, string(out))
-		return
+// CommitPaths stages paths and commits them with commitMsg, then pushes
+// like GitAddAndCommit. Unlike it, failures are returned and leave nothing
+// staged, so callers can undo their changes.
+func CommitPaths(store string, paths []string, commitMsg string, stderr io.Writer) error {
+	if !hasGit(store) {
+		return nil
+	}
+	unlock, err := LockStore(store)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	commit := exec.Command("git", "commit", "-m", commitMsg)
-	commit.Dir = store
-	if out, err := commit.CombinedOutput(); err != nil {
-		fmt.Fprintf(stderr, "warning: git commit failed: %v: %s\n", err, string(out))
+	if out, err := runGit(store, append([]string{"add", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("git add failed: %w: %s", err, string(out))
 	}
-	PushIfRemote(store, stderr)
+	if out, err := runGit(store, "commit", "-m", commitMsg); err != nil {
+		_, _ = runGit(store, append([]string{"reset", "-q", "--"}, paths...)...)
+		return fmt.Errorf("git commit failed: %w: %s", err, string(out))
+	}
+	PushAfterCommit(store, stderr)
+	return nil
 }
 
 // GitRmAndCommit attempts to stage deletions and commit with commitMsg.
@@ -49,21 +98,57 @@ func GitRmAndCommit(store string, paths []string, commitMsg string, stderr io.Wr
 	if stderr == nil {
 		stderr = io.Discard
 	}
+	if stageAndCommit(store, append([]string{"rm", "-f"}, paths...), commitMsg, stderr) {
+		PushAfterCommit(store, stderr)
+	}
+}
+
+// stageAndCommit runs the git staging command and commits while holding the
+// store lock. It returns false if staging failed and nothing was committed.
+func stageAndCommit(store string, stageArgs []string, commitMsg string, stderr io.Writer) bool {
+	unlock, err := LockStore(store)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: git commit skipped: %v\n", err)
+		return false
+	}
+	defer unlock()
 
-	rmArgs := append([]string{"rm", "-f"}, paths...)
-	rm := exec.Command("git", rmArgs...)
-	rm.Dir = store
-	if out, err := rm.CombinedOutput(); err != nil {
-		fmt.Fprintf(stderr, "warning: git rm failed: %v: %s\n", err, string(out))
-		return
+	if out, err := runGit(store, stageArgs...); err != nil {
+		fmt.Fprintf(stderr, "warning: git %s failed: %v: %s\n", stageArgs[0], err, string(out))
+		return false
 	}
 
-	commit := exec.Command("git", "commit", "-m", commitMsg)
-	commit.Dir = store
-	if out, err := commit.CombinedOutput(); err != nil {
+	if out, err := runGit(store, "commit", "-m", commitMsg); err != nil {
 		fmt.Fprintf(stderr, "warning: git commit failed: %v: %s\n", err, string(out))
 	}
-	PushIfRemote(store, stderr)
+	return true
+}
+
+// EntryRevisions lists the commits touching an entry, newest first, as
+// "<short sha>\t<subject>" lines.
+func EntryRevisions(store, name string) ([]string, error) {
+	if !hasGit(store) {
+		return nil, fmt.Errorf("git is not enabled for this store")
+	}
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	_, ext, err := ExistingEntryPath(store, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := runGit(store, "log", "--follow", "--format=%h%x09%s", "--", name+ext)
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w: %s", err, string(out))
+	}
+	var revs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			revs = append(revs, line)
+		}
+	}
+	return revs, nil
 }
 
 func RevertLastCommitForPath(store, path string, stderr io.Writer) error {
@@ -74,9 +159,13 @@ func RevertLastCommitForPath(store, path string, stderr io.Writer) error {
 		stderr = io.Discard
 	}
 
-	logCmd := exec.Command("git", "log", "-n1", "--format=%H", "--", path)
-	logCmd.Dir = store
-	shaOut, err := logCmd.CombinedOutput()
+	unlock, err := LockStore(store)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	shaOut, err := runGit(store, "log", "-n1", "--format=%H", "--", path)
 	if err != nil {
 		return fmt.Errorf("git log failed: %w: %s", err, string(shaOut))
 	}
@@ -84,9 +173,7 @@ func RevertLastCommitForPath(store, path string, stderr io.Writer) error {
 	if sha == "" {
 		return fmt.Errorf("no commits found for %s", path)
 	}
-	revert := exec.Command("git", "revert", "--no-edit", sha)
-	revert.Dir = store
-	if out, err := revert.CombinedOutput(); err != nil {
+	if out, err := runGit(store, "revert", "--no-edit", sha); err != nil {
 		return fmt.Errorf("git revert failed: %w: %s", err, string(out))
 	}
 	return nil
@@ -111,29 +198,83 @@ func Sync(store string, stdout, stderr io.Writer) error {
 	}
 
 	// Check if remote exists
-	remoteCmd := exec.Command("git", "config", "--get", "remote.origin.url")
-	remoteCmd.Dir = store
-	if err := remoteCmd.Run(); err != nil {
+	if !HasRemote(store) {
 		return nil // No remote configured, nothing to do
 	}
 
+	// The rebase rewrites the working tree, so it needs the lock; the push
+	// only reads refs and may take a while on a slow network.
+	unlock, err := LockStore(store)
+	if err != nil {
+		return err
+	}
+
 	// 1. Pull --rebase
-	pull := exec.Command("git", "pull", "--rebase", "origin", "HEAD")
-	pull.Dir = store
-	pull.Stdout = stdout
-	pull.Stderr = stderr
 	// We ignore pull errors (e.g. empty remote) and try to push anyway.
 	// Real sync issues will likely cause push to fail too.
-	_ = pull.Run()
+	out, err := runGit(store, "pull", "--rebase", "origin", "HEAD")
+	unlock()
+	if err != nil {
+		_, _ = stderr.Write(out)
+	} else {
+		_, _ = stdout.Write(out)
+	}
 
 	// 2. Push
-	push := exec.Command("git", "push", "-u", "origin", "HEAD")
-	push.Dir = store
-	push.Stdout = stdout
-	push.Stderr = stderr
-	if err := push.Run(); err != nil {
+	out, err = runGit(store, "push", "-u", "origin", "HEAD")
+	if err != nil {
+		_, _ = stderr.Write(out)
 		return fmt.Errorf("git push failed: %w", err)
 	}
+	_, _ = stdout.Write(out)
 
 	return nil
 }
+
+// CommitStoreChanges stages every change in the store, including files
+// edited outside pea, and commits them with commitMsg. It reports whether
+// a commit was made.
+func CommitStoreChanges(store, commitMsg string) (bool, error) {
+	if !hasGit(store) {
+		return false, fmt.Errorf("git is not enabled")
+	}
+	unlock, err := LockStore(store)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if out, err := runGit(store, "add", "-A", "."); err != nil {
+		return false, fmt.Errorf("git add failed: %w: %s", err, string(out))
+	}
+
+	if _, err := runGit(store, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+
+	if out, err := runGit(store, "commit", "-m", commitMsg); err != nil {
+		return false, fmt.Errorf("git commit failed: %w: %s", err, string(out))
+	}
+	return true, nil
+}
+
+// EnsureInitialCommit creates an empty first commit if the store has none,
+// so there is a branch to push. It reports whether a commit was made.
+func EnsureInitialCommit(store string) (bool, error) {
+	if !hasGit(store) {
+		return false, fmt.Errorf("git is not enabled")
+	}
+	unlock, err := LockStore(store)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	if _, err := runGit(store, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		return false, nil
+	}
+	if out, err := runGit(store, "commit", "--allow-empty", "-m", "chore: initial commit"); err != nil {
+		return false, fmt.Errorf("failed to create initial commit: %w: %s", err, string(out))
+	}
+	return true, nil
+}
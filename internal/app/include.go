@@ -0,0 +1,179 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxIncludeDepth bounds how deeply includes may nest.
+const MaxIncludeDepth = 10
+
+// includeRe matches include directives: {{> name}} or {{> name@rev}}.
+var includeRe = regexp.MustCompile(`\{\{>\s*([^\s{}@]+)(?:@([^\s{}]+))?\s*\}\}`)
+
+// Include is an include directive found in an entry.
+type Include struct {
+	Name string // as written in the directive
+	Rev  string // pinned git revision, empty to follow the includer
+	Raw  string // the full directive text
+}
+
+// ParseIncludes returns the include directives in b, in order of appearance.
+func ParseIncludes(b []byte) []Include {
+	var out []Include
+	for _, m := range includeRe.FindAllSubmatch(b, -1) {
+		out = append(out, Include{Name: string(m[1]), Rev: string(m[2]), Raw: string(m[0])})
+	}
+	return out
+}
+
+// includeKey identifies an entry at a revision on the include stack.
+func includeKey(name, rev string) string {
+	if rev == "" {
+		return name
+	}
+	return name + "@" + rev
+}
+
+// expandIncludes replaces include directives in b with the referenced
+// entries, recursively. Unpinned includes are read at the includer's rev,
+// and included collections are rendered like 'pea get' would. stack holds
+// the chain of entries being expanded or rendered, for cycle detection.
+func expandIncludes(store string, b []byte, rev string, stack []string) ([]byte, error) {
+	if !includeRe.Match(b) {
+		return b, nil
+	}
+	var firstErr error
+	out := includeRe.ReplaceAllFunc(b, func(m []byte) []byte {
+		if firstErr != nil {
+			return m
+		}
+		sub := includeRe.FindSubmatch(m)
+		name, err := NormalizeName(string(sub[1]))
+		if err != nil {
+			firstErr = fmt.Errorf("include in %s: %w", stack[len(stack)-1], err)
+			return m
+		}
+		childRev := rev
+		if len(sub[2]) > 0 {
+			childRev = string(sub[2])
+		}
+		key := includeKey(name, childRev)
+		for _, k := range stack {
+			if k == key {
+				firstErr = fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), key)
+				return m
+			}
+		}
+		if len(stack) > MaxIncludeDepth {
+			firstErr = fmt.Errorf("include depth exceeds %d: %s -> %s", MaxIncludeDepth, strings.Join(stack, " -> "), key)
+			return m
+		}
+		file, err := ReadEntryFileAt(store, name, childRev)
+		if err != nil {
+			firstErr = fmt.Errorf("include in %s: %w", stack[len(stack)-1], err)
+			return m
+		}
+		var child []byte
+		if IsCollection(file) {
+			child, err = renderCollection(store, childRev, file, nil, append(stack, key))
+		} else {
+			child, err = expandIncludes(store, StripFrontMatter(file), childRev, append(stack, key))
+		}
+		if err != nil {
+			firstErr = err
+			return m
+		}
+		// The directive usually sits on its own line; drop the included
+		// entry's final newline so the line break isn't doubled.
+		return []byte(strings.TrimSuffix(string(child), "\n"))
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// IncludeNode is an entry in the include graph built by IncludeGraph.
+type IncludeNode struct {
+	Name     string
+	Rev      string
+	Children []IncludeNode
+	// Problem is set when the include cannot be followed ("missing", "cycle", ...).
+	Problem string
+}
+
+// IncludeGraph returns the tree of includes reachable from name at rev.
+func IncludeGraph(store, name, rev string) (IncludeNode, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return IncludeNode{}, err
+	}
+	refs, err := entryRefs(store, name, rev)
+	if err != nil {
+		return IncludeNode{}, err
+	}
+	root := IncludeNode{Name: name, Rev: rev}
+	root.Children = includeChildren(store, refs, rev, []string{includeKey(name, rev)})
+	return root, nil
+}
+
+// entryRefs returns the entries name pulls in: its include directives, or
+// its members if it is a collection.
+func entryRefs(store, name, rev string) ([]Include, error) {
+	file, err := ReadEntryFileAt(store, name, rev)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCollection(file) {
+		return ParseIncludes(StripFrontMatter(file)), nil
+	}
+	members, _ := ParseCollection(StripFrontMatter(file))
+	refs := make([]Include, 0, len(members))
+	for _, m := range members {
+		refs = append(refs, Include{Name: m.Name})
+	}
+	return refs, nil
+}
+
+func includeChildren(store string, refs []Include, rev string, stack []string) []IncludeNode {
+	var nodes []IncludeNode
+	for _, inc := range refs {
+		node := IncludeNode{Name: inc.Name, Rev: inc.Rev}
+		childRev := rev
+		if inc.Rev != "" {
+			childRev = inc.Rev
+		}
+		name, err := NormalizeName(inc.Name)
+		if err != nil {
+			node.Problem = "invalid name"
+			nodes = append(nodes, node)
+			continue
+		}
+		node.Name = name
+		key := includeKey(name, childRev)
+		cycle := false
+		for _, k := range stack {
+			if k == key {
+				cycle = true
+				break
+			}
+		}
+		switch {
+		case cycle:
+			node.Problem = "cycle"
+		case len(stack) > MaxIncludeDepth:
+			node.Problem = "too deep"
+		default:
+			refs, err := entryRefs(store, name, childRev)
+			if err != nil {
+				node.Problem = "missing"
+				break
+			}
+			node.Children = includeChildren(store, refs, childRev, append(stack, key))
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateJSONSchema checks data against a JSON Schema and returns the
+// violations found. It supports the subset prompt tests need: type, enum,
+// const, required, properties, additionalProperties, items, minimum,
+// maximum, minLength, maxLength, minItems and maxItems. Schemas using any
+// other keyword are rejected rather than half-checked.
+func ValidateJSONSchema(schema, data []byte) ([]string, error) {
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if err := checkSchemaKeywords(s, "$"); err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []string{fmt.Sprintf("output is not valid JSON: %v", err)}, nil
+	}
+	var problems []string
+	validateSchema(s, v, "$", &problems)
+	return problems, nil
+}
+
+// schemaKeywords are the keywords ValidateJSONSchema understands; the
+// annotations only describe the schema and need no checking.
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "required": true,
+	"properties": true, "additionalProperties": true, "items": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true,
+	"minItems": true, "maxItems": true,
+	// annotations
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true,
+}
+
+// checkSchemaKeywords fails on the first keyword in s or its subschemas
+// that ValidateJSONSchema does not support.
+func checkSchemaKeywords(s map[string]any, path string) error {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !schemaKeywords[k] {
+			return fmt.Errorf("unsupported schema keyword %q at %s", k, path)
+		}
+	}
+	props, _ := s["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for k := range props {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		if ps, ok := props[k].(map[string]any); ok {
+			if err := checkSchemaKeywords(ps, path+"."+k); err != nil {
+				return err
+			}
+		}
+	}
+	if aps, ok := s["additionalProperties"].(map[string]any); ok {
+		if err := checkSchemaKeywords(aps, path+".*"); err != nil {
+			return err
+		}
+	}
+	if items, ok := s["items"]; ok {
+		// Tuple validation (an array of schemas) is not supported.
+		sub, ok := items.(map[string]any)
+		if !ok {
+			return fmt.Errorf("unsupported schema: \"items\" at %s must be a single schema object", path)
+		}
+		if err := checkSchemaKeywords(sub, path+"[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSchema(s map[string]any, v any, path string, problems *[]string) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, v) {
+		fail("expected type %v, got %s", t, jsonType(v))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %s is not one of the allowed values", jsonString(v))
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, v) {
+		fail("expected %s, got %s", jsonString(c), jsonString(v))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if req, ok := s["required"].([]any); ok {
+			for _, r := range req {
+				if name, _ := r.(string); name != "" {
+					if _, ok := val[name]; !ok {
+						fail("missing required property %q", name)
+					}
+				}
+			}
+		}
+		props, _ := s["properties"].(map[string]any)
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k].(map[string]any); ok {
+				validateSchema(ps, val[k], path+"."+k, problems)
+			} else if ap, ok := s["additionalProperties"].(bool); ok && !ap {
+				fail("unexpected property %q", k)
+			} else if aps, ok := s["additionalProperties"].(map[string]any); ok {
+				validateSchema(aps, val[k], path+"."+k, problems)
+			}
+		}
+	case []any:
+		if n, ok := schemaNumber(s, "minItems"); ok && float64(len(val)) < n {
+			fail("expected at least %v items, got %d", n, len(val))
+		}
+		if n, ok := schemaNumber(s, "maxItems"); ok && float64(len(val)) > n {
+			fail("expected at most %v items, got %d", n, len(val))
+		}
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range val {
+				validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case string:
+		l := float64(utf8.RuneCountInString(val))
+		if n, ok := schemaNumber(s, "minLength"); ok && l < n {
+			fail("expected at least %v characters, got %v", n, l)
+		}
+		if n, ok := schemaNumber(s, "maxLength"); ok && l > n {
+			fail("expected at most %v characters, got %v", n, l)
+		}
+	case float64:
+		if n, ok := schemaNumber(s, "minimum"); ok && val < n {
+			fail("expected at least %v, got %v", n, val)
+		}
+		if n, ok := schemaNumber(s, "maximum"); ok && val > n {
+			fail("expected at most %v, got %v", n, val)
+		}
+	}
+}
+
+func schemaNumber(s map[string]any, key string) (float64, bool) {
+	n, ok := s[key].(float64)
+	return n, ok
+}
+
+func matchesType(t, v any) bool {
+	switch tt := t.(type) {
+	case string:
+		return typeIs(tt, v)
+	case []any:
+		for _, x := range tt {
+			if name, ok := x.(string); ok && typeIs(name, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeIs(name string, v any) bool {
+	switch name {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return jsonType(v) == name
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func jsonString(v any) string {
+	b, _ := json.Marshal(v)
+	return strings.TrimSpace(string(b))
+}
+
+func jsonEqual(a, b any) bool {
+	return jsonString(a) == jsonString(b)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "review",
+		"type": "object",
+		"required": ["verdict", "issues"],
+		"additionalProperties": false,
+		"properties": {
+			"verdict": {"enum": ["approve", "reject"]},
+			"score": {"type": "integer", "minimum": 0, "maximum": 10},
+			"summary": {"type": "string", "minLength": 3, "maxLength": 10},
+			"issues": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"version": {"const": 1}
+		}
+	}`
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid",
+			data: `{"verdict": "approve", "score": 7, "summary": "fine", "issues": [], "version": 1}`,
+		},
+		{
+			name: "missing required",
+			data: `{"verdict": "approve"}`,
+			want: []string{`$: missing required property "issues"`},
+		},
+		{
+			name: "wrong values",
+			data: `{"verdict": "maybe", "score": 7.5, "summary": "ok", "issues": ["a", 2, "c"], "version": 2, "extra": true}`,
+			want: []string{
+				`$: unexpected property "extra"`,
+				`$.issues: expected at most 2 items, got 3`,
+				`$.issues[1]: expected type string, got number`,
+				`$.score: expected type integer, got number`,
+				`$.summary: expected at least 3 characters, got 2`,
+				`$.verdict: value "maybe" is not one of the allowed values`,
+				`$.version: expected 1, got 2`,
+			},
+		},
+		{
+			name: "out of range",
+			data: `{"verdict": "reject", "score": 11, "summary": "far too long", "issues": []}`,
+			want: []string{
+				`$.score: expected at most 10, got 11`,
+				`$.summary: expected at most 10 characters, got 12`,
+			},
+		},
+		{
+			name: "not JSON",
+			data: `verdict: approve`,
+			want: []string{"output is not valid JSON: invalid character 'v' looking for beginning of value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateJSONSchema([]byte(schema), []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateJSONSchemaRejectsUnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"type": "string", "pattern": "^a"}`, `unsupported schema keyword "pattern" at $`},
+		{`{"$ref": "#/$defs/x"}`, `unsupported schema keyword "$ref" at $`},
+		{`{"oneOf": [{"type": "string"}]}`, `unsupported schema keyword "oneOf" at $`},
+		{`{"properties": {"when": {"type": "string", "format": "date"}}}`, `unsupported schema keyword "format" at $.when`},
+		{`{"items": {"exclusiveMinimum": 0}}`, `unsupported schema keyword "exclusiveMinimum" at $[]`},
+		{`{"additionalProperties": {"anyOf": []}}`, `unsupported schema keyword "anyOf" at $.*`},
+		{`{"items": [{"type": "string"}, {"type": "number"}]}`, `unsupported schema: "items" at $ must be a single schema object`},
+		{`{"properties": {"xs": {"items": true}}}`, `unsupported schema: "items" at $.xs must be a single schema object`},
+	}
+	for _, tt := range tests {
+		_, err := ValidateJSONSchema([]byte(tt.schema), []byte(`{}`))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("schema %s: got error %v, want %q", tt.schema, err, tt.want)
+		}
+	}
+}
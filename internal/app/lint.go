@@ -0,0 +1,261 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Lint severities. Errors fail 'pea lint'; warnings only do with --strict.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// DefaultLintMaxBytes is the entry size above which lint warns.
+const DefaultLintMaxBytes = 64 * 1024
+
+// LintProblem is a single finding reported by LintEntries.
+type LintProblem struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// LintOptions tunes LintEntries.
+type LintOptions struct {
+	// MaxBytes is the size above which an entry is reported; zero uses
+	// DefaultLintMaxBytes.
+	MaxBytes int64
+	// Staged lints the content staged in git, which is what a pre-commit
+	// hook is about to commit, instead of the working tree.
+	Staged bool
+}
+
+// frontMatterKeyRe matches a top-level 'key:' line in front matter.
+var frontMatterKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*:`)
+
+// LintEntries checks the named entries, or every entry when names is empty,
+// and returns the number of entries checked and the problems found. Names
+// given as file paths, as a pre-commit hook passes them, are skipped when
+// they are not entry files or no longer exist (or, with opts.Staged, are not
+// staged).
+func LintEntries(store string, names []string, opts LintOptions) (int, []LintProblem, error) {
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = DefaultLintMaxBytes
+	}
+	if len(names) == 0 {
+		all, err := ListEntries(store)
+		if err != nil {
+			return 0, nil, err
+		}
+		names = all
+	}
+
+	var problems []LintProblem
+	checked := 0
+	for _, raw := range names {
+		isPath := filepath.Ext(raw) != "" || strings.ContainsRune(raw, '/')
+		if isPath && !isEntryFile(filepath.Base(raw)) {
+			continue
+		}
+		name, err := NormalizeName(strings.TrimSuffix(strings.TrimSuffix(filepath.Base(raw), DefaultExt), LegacyExt))
+		if err != nil {
+			return 0, nil, err
+		}
+		file := ""
+		if isPath {
+			file = filepath.Base(raw)
+		}
+		b, ext, err := readLintEntry(store, name, file, opts.Staged)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				if isPath {
+					continue // deleted in the commit being checked
+				}
+				return 0, nil, fmt.Errorf("not found: %s", name)
+			}
+			return 0, nil, err
+		}
+		checked++
+
+		report := func(line int, check, severity, format string, args ...any) {
+			problems = append(problems, LintProblem{
+				Name:     name,
+				File:     name + ext,
+				Line:     line,
+				Check:    check,
+				Severity: severity,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		if ext == DefaultExt && FileExists(LegacyEntryPath(store, name)) {
+			report(0, "duplicate", SeverityError, "%s%s is shadowed by %s%s", name, LegacyExt, name, DefaultExt)
+		}
+		lintFrontMatter(b, report)
+		lintTemplateVars(b, report)
+		lintIncludes(store, name, b, report)
+		lintCollection(store, name, b, report)
+		if err := CheckTokenBudget(store, name, b); err != nil {
+			report(0, "tokens", SeverityError, "%v", err)
+		}
+		if size := int64(len(b)); size > opts.MaxBytes {
+			report(0, "size", SeverityWarning, "entry is %d bytes, over the %d byte limit", size, opts.MaxBytes)
+		}
+		for i, line := range strings.Split(string(b), "\n") {
+			if strings.TrimRight(line, " \t") != line {
+				report(i+1, "whitespace", SeverityWarning, "trailing whitespace")
+			}
+		}
+	}
+	return checked, problems, nil
+}
+
+// readLintEntry returns the content and extension of entry name, read from
+// file when it is given or else from whichever entry file exists. With
+// staged, the copy in the git index is read. A missing entry is reported as
+// os.ErrNotExist.
+func readLintEntry(store, name, file string, staged bool) ([]byte, string, error) {
+	if !staged {
+		path, ext, err := ExistingEntryPath(store, name)
+		if err != nil {
+			return nil, "", err
+		}
+		b, err := os.ReadFile(path)
+		return b, ext, err
+	}
+	if !hasGit(store) {
+		return nil, "", fmt.Errorf("--staged needs git to be enabled for the store")
+	}
+	if file == "" {
+		file = name + DefaultExt
+		if !IsTracked(store, file) {
+			file = name + LegacyExt
+		}
+	}
+	if !IsTracked(store, file) {
+		return nil, "", os.ErrNotExist
+	}
+	b, err := runGit(store, "show", ":"+file)
+	if err != nil {
+		return nil, "", fmt.Errorf("git show :%s failed: %w: %s", file, err, string(b))
+	}
+	return b, filepath.Ext(file), nil
+}
+
+// isEntryFile reports whether a file name in the store holds an entry,
+// as opposed to a dotfile or a sibling such as name.tests.toml.
+func isEntryFile(base string) bool {
+	ext := filepath.Ext(base)
+	return (ext == DefaultExt || ext == LegacyExt) && !strings.HasPrefix(base, ".")
+}
+
+type lintReporter func(line int, check, severity, format string, args ...any)
+
+// lintFrontMatter flags front matter that StripFrontMatter would silently
+// leave in the body, and lines that are not 'key: value' pairs or their
+// indented continuations.
+func lintFrontMatter(b []byte, report lintReporter) {
+	first, _, _ := strings.Cut(string(b), "\n")
+	if strings.TrimSpace(first) != "---" {
+		return
+	}
+	lines, ok := frontMatterLines(b)
+	if !ok {
+		report(1, "front-matter", SeverityError, "front matter is not closed with '---'")
+		return
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "- ") {
+			continue
+		}
+		if !frontMatterKeyRe.MatchString(line) {
+			report(i+2, "front-matter", SeverityError, "malformed front matter line %q", line)
+		}
+	}
+}
+
+// lintTemplateVars flags placeholders that are not declared under 'vars:'.
+// Entries without 'vars:' only get a warning: 'pea get' prints them as-is,
+// so the braces may well be literal text.
+func lintTemplateVars(b []byte, report lintReporter) {
+	decl := DeclaredVars(b)
+	declared := make(map[string]struct{})
+	for _, v := range decl {
+		declared[v.Name] = struct{}{}
+	}
+	body := StripFrontMatter(b)
+	offset := strings.Count(string(b[:len(b)-len(body)]), "\n")
+	for i, line := range strings.Split(string(body), "\n") {
+		for _, name := range TemplateVars([]byte(line)) {
+			if _, ok := declared[name]; ok {
+				continue
+			}
+			if len(decl) == 0 {
+				report(offset+i+1, "template", SeverityWarning, "template variable %q is used but the entry declares no vars", name)
+			} else {
+				report(offset+i+1, "template", SeverityError, "template variable %q is not declared in vars", name)
+			}
+		}
+	}
+}
+
+// lintIncludes expands the entry's includes and reports the first failure,
+// such as a missing entry or a cycle.
+func lintIncludes(store, name string, b []byte, report lintReporter) {
+	if len(ParseIncludes(StripFrontMatter(b))) == 0 {
+		return
+	}
+	if _, err := ReadEntry(store, name, ""); err != nil {
+		report(0, "include", SeverityError, "%v", err)
+	}
+}
+
+// lintCollection flags collection members that are invalid or missing, and
+// collections whose members lead back to them.
+func lintCollection(store, name string, b []byte, report lintReporter) {
+	if !IsCollection(b) {
+		return
+	}
+	body := StripFrontMatter(b)
+	offset := strings.Count(string(b[:len(b)-len(body)]), "\n")
+	members, err := ParseCollection(body)
+	if err != nil {
+		report(0, "collection", SeverityError, "%v", err)
+		return
+	}
+	for _, m := range members {
+		member, err := NormalizeName(m.Name)
+		if err != nil {
+			report(offset+m.Line, "collection", SeverityError, "%v", err)
+			continue
+		}
+		if _, _, err := ExistingEntryPath(store, member); err != nil {
+			report(offset+m.Line, "collection", SeverityError, "member not found: %s", member)
+		}
+	}
+	graph, err := IncludeGraph(store, name, "")
+	if err == nil && hasCycle(graph) {
+		report(0, "collection", SeverityError, "cycle in collection %s (see pea deps %s)", name, name)
+	}
+}
+
+func hasCycle(n IncludeNode) bool {
+	if n.Problem == "cycle" {
+		return true
+	}
+	for _, c := range n.Children {
+		if hasCycle(c) {
+			return true
+		}
+	}
+	return false
+}
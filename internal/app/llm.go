@@ -0,0 +1,186 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLLMEndpoint is the OpenAI-compatible API base used by 'pea ask'
+// unless configured otherwise.
+const DefaultLLMEndpoint = "https://api.openai.com/v1"
+
+// LLMConfig configures the chat completion endpoint used by 'pea ask'.
+type LLMConfig struct {
+	Endpoint string `toml:"endpoint,omitempty"`
+	APIKey   string `toml:"api_key,omitempty"`
+	Model    string `toml:"model,omitempty"`
+}
+
+// GetLLMConfig returns the [llm] config with PEA_LLM_ENDPOINT,
+// PEA_LLM_API_KEY (or OPENAI_API_KEY) and PEA_LLM_MODEL applied on top.
+func GetLLMConfig() LLMConfig {
+	conf := ReadConfig().LLM
+	if v := os.Getenv("PEA_LLM_ENDPOINT"); v != "" {
+		conf.Endpoint = v
+	}
+	if v := os.Getenv("PEA_LLM_API_KEY"); v != "" {
+		conf.APIKey = v
+	} else if conf.APIKey == "" {
+		conf.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if v := os.Getenv("PEA_LLM_MODEL"); v != "" {
+		conf.Model = v
+	}
+	if conf.Endpoint == "" {
+		conf.Endpoint = DefaultLLMEndpoint
+	}
+	return conf
+}
+
+// ChatMessage is one turn of a chat completion request.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest is the body of a /chat/completions request.
+type ChatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream"`
+}
+
+// ChatOptions reads the model and temperature from an entry's front
+// matter. The model falls back to the configured one.
+func ChatOptions(entryFile []byte, conf LLMConfig) (model string, temperature *float64, err error) {
+	model = conf.Model
+	if v, ok := FrontMatterValue(entryFile, "model"); ok && v != "" {
+		model = v
+	}
+	if v, ok := FrontMatterValue(entryFile, "temperature"); ok && v != "" {
+		t, perr := strconv.ParseFloat(v, 64)
+		if perr != nil {
+			return "", nil, fmt.Errorf("invalid temperature %q: %w", v, perr)
+		}
+		temperature = &t
+	}
+	return model, temperature, nil
+}
+
+// ChatClient talks to an OpenAI-compatible chat completion endpoint, such
+// as OpenAI itself, llama.cpp's server, Ollama or vLLM.
+type ChatClient struct {
+	endpoint string
+	apiKey   string
+	http     *http.Client
+}
+
+func NewChatClient(conf LLMConfig) *ChatClient {
+	return &ChatClient{
+		endpoint: strings.TrimRight(conf.Endpoint, "/"),
+		apiKey:   conf.APIKey,
+		// No overall timeout: long answers stream for a while. Local
+		// models can be slow to load, so allow a generous first byte.
+		http: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 2 * time.Minute,
+		}},
+	}
+}
+
+// Stream sends req with streaming enabled, writes the reply to w as it
+// arrives and returns the full reply. Servers that ignore 'stream' and
+// answer with a single JSON body are handled too.
+func (c *ChatClient) Stream(req ChatRequest, w io.Writer) (string, error) {
+	req.Stream = true
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.endpoint+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %w", c.endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%s returned %s: %s", c.endpoint, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var body struct {
+			Choices []struct {
+				Message ChatMessage `json:"message"`
+			} `json:"choices"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return "", fmt.Errorf("invalid response from %s: %w", c.endpoint, err)
+		}
+		if len(body.Choices) == 0 {
+			return "", fmt.Errorf("empty response from %s", c.endpoint)
+		}
+		text := body.Choices[0].Message.Content
+		_, err := io.WriteString(w, text)
+		return text, err
+	}
+
+	var reply strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta ChatMessage `json:"delta"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return reply.String(), fmt.Errorf("invalid stream chunk from %s: %w", c.endpoint, err)
+		}
+		if chunk.Error != nil {
+			return reply.String(), fmt.Errorf("%s: %s", c.endpoint, chunk.Error.Message)
+		}
+		for _, ch := range chunk.Choices {
+			if ch.Delta.Content == "" {
+				continue
+			}
+			reply.WriteString(ch.Delta.Content)
+			if _, err := io.WriteString(w, ch.Delta.Content); err != nil {
+				return reply.String(), err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return reply.String(), fmt.Errorf("reading stream from %s: %w", c.endpoint, err)
+	}
+	return reply.String(), nil
+}
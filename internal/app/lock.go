@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// lockTimeout bounds how long a command waits for another pea process
+// (for example 'pea sync --watch') to release the store lock.
+const lockTimeout = 30 * time.Second
+
+var errLocked = errors.New("store is locked")
+
+type storeLock struct {
+	f *os.File
+	// token is written to the lock file while it is held and handed to
+	// child processes through lockHeldEnv.
+	token string
+	// owner is the goroutine holding the lock; only it may re-enter.
+	owner uint64
+	depth int
+	// done is closed when the lock is released, waking other goroutines
+	// of this process that wait for it.
+	done chan struct{}
+	// released runs once the outermost holder releases the lock.
+	released []func()
+}
+
+var (
+	locksMu sync.Mutex
+	locks   = make(map[string]*storeLock)
+)
+
+// lockHeldEnv is set on git processes started while the lock is held, so
+// that pea commands run from git hooks (e.g. 'pea lint' in pre-commit) share
+// the parent's lock instead of waiting for it. Its value is the token the
+// holder wrote to the lock file, so only children of the holder can skip
+// locking, and only while the lock is held.
+const lockHeldEnv = "PEA_STORE_LOCKED"
+
+// lockFileName lives in the store root (not .git) so the same file guards
+// the store before and after git is initialized; it is excluded from git.
+const lockFileName = ".pea.lock"
+
+func lockPath(store string) string {
+	return filepath.Join(store, lockFileName)
+}
+
+// goroutineID identifies the calling goroutine. The runtime doesn't export
+// it, so it is read from the first line of the goroutine's stack trace
+// ("goroutine 42 [running]:").
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// LockStore acquires the store-wide advisory lock that serializes git index
+// and content writes between concurrent pea processes and between
+// goroutines of one process. The lock is reentrant for the goroutine holding
+// it; call the returned function to release it.
+func LockStore(store string) (func(), error) {
+	if heldByParent(store) {
+		return func() {}, nil
+	}
+
+	me := goroutineID()
+	p := lockPath(store)
+	deadline := time.Now().Add(lockTimeout)
+	timedOut := fmt.Errorf("timed out waiting for lock %s: another pea process is running", p)
+
+	// Claim the lock within this process first, waiting for any other
+	// goroutine that holds it without keeping locksMu.
+	locksMu.Lock()
+	for {
+		l, ok := locks[store]
+		if !ok {
+			break
+		}
+		if l.owner == me {
+			l.depth++
+			locksMu.Unlock()
+			return func() { unlockStore(store) }, nil
+		}
+		locksMu.Unlock()
+		select {
+		case <-l.done:
+		case <-time.After(time.Until(deadline)):
+			return nil, timedOut
+		}
+		locksMu.Lock()
+	}
+	l := &storeLock{owner: me, depth: 1, done: make(chan struct{})}
+	locks[store] = l
+	locksMu.Unlock()
+
+	f, token, err := lockFile(p, deadline)
+	if err != nil {
+		locksMu.Lock()
+		delete(locks, store)
+		locksMu.Unlock()
+		close(l.done)
+		if errors.Is(err, errLocked) {
+			return nil, timedOut
+		}
+		return nil, err
+	}
+	locksMu.Lock()
+	l.f, l.token = f, token
+	locksMu.Unlock()
+	return func() { unlockStore(store) }, nil
+}
+
+// lockFile takes the flock on p, polling until deadline while another
+// process holds it, and writes a fresh holder token to it.
+func lockFile(p string, deadline time.Time) (*os.File, string, error) {
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, "", fmt.Errorf("open lock %s: %w", p, err)
+	}
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, "", fmt.Errorf("lock %s: %w", p, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, "", err
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+	if err := f.Truncate(0); err == nil {
+		_, err = f.WriteAt([]byte(token), 0)
+	}
+	if err != nil {
+		_ = unlockFile(f)
+		f.Close()
+		return nil, "", fmt.Errorf("write lock %s: %w", p, err)
+	}
+	return f, token, nil
+}
+
+// heldByParent reports whether this process was started by a pea process
+// that holds the store lock, as shown by a lockHeldEnv token matching the
+// one in the lock file.
+func heldByParent(store string) bool {
+	token := os.Getenv(lockHeldEnv)
+	if token == "" {
+		return false
+	}
+	b, err := os.ReadFile(lockPath(store))
+	return err == nil && string(b) == token
+}
+
+func unlockStore(store string) {
+	locksMu.Lock()
+	l, ok := locks[store]
+	if !ok {
+		locksMu.Unlock()
+		return
+	}
+	l.depth--
+	if l.depth > 0 {
+		locksMu.Unlock()
+		return
+	}
+	_ = l.f.Truncate(0)
+	_ = unlockFile(l.f)
+	_ = l.f.Close()
+	delete(locks, store)
+	locksMu.Unlock()
+	close(l.done)
+
+	for _, fn := range l.released {
+		fn()
+	}
+}
+
+// heldLock returns the store lock if the calling goroutine holds it.
+// locksMu must be held.
+func heldLock(store string) (*storeLock, bool) {
+	l, ok := locks[store]
+	if !ok || l.owner != goroutineID() {
+		return nil, false
+	}
+	return l, true
+}
+
+// afterUnlock runs fn once the calling goroutine no longer holds the store
+// lock: right away if it does not hold it now, else when the outermost
+// holder releases it. Slow work such as pushing uses it so that a command
+// holding the lock across a commit doesn't block other pea processes on the
+// network.
+func afterUnlock(store string, fn func()) {
+	locksMu.Lock()
+	l, ok := heldLock(store)
+	if ok {
+		l.released = append(l.released, fn)
+	}
+	locksMu.Unlock()
+	if !ok {
+		fn()
+	}
+}
+
+// lockEnv returns the environment for a child process of a command that may
+// hold the store lock.
+func lockEnv(store string) []string {
+	locksMu.Lock()
+	l, held := heldLock(store)
+	locksMu.Unlock()
+	if !held {
+		return nil
+	}
+	return append(os.Environ(), lockHeldEnv+"="+l.token)
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package app
+
+import "os"
+
+// Advisory locking is only implemented for unix platforms; elsewhere the
+// lock is a no-op and concurrent invocations are not serialized.
+func tryLockFile(_ *os.File) error { return nil }
+
+func unlockFile(_ *os.File) error { return nil }
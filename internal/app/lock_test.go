@@ -0,0 +1,82 @@
+package app
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockStoreReentrantPerGoroutine(t *testing.T) {
+	store := t.TempDir()
+
+	unlock, err := LockStore(store)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	inner, err := LockStore(store)
+	if err != nil {
+		t.Fatalf("nested lock: %v", err)
+	}
+	inner()
+
+	acquired := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		u, err := LockStore(store)
+		if err != nil {
+			t.Errorf("lock from second goroutine: %v", err)
+			return
+		}
+		close(acquired)
+		u()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second goroutine entered the lock while it was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Other stores stay available while this one is held.
+	other, err := LockStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("lock other store: %v", err)
+	}
+	other()
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second goroutine did not get the lock after release")
+	}
+	wg.Wait()
+}
+
+func TestLockHeldEnvRequiresHolderToken(t *testing.T) {
+	store := t.TempDir()
+
+	t.Setenv(lockHeldEnv, store)
+	if heldByParent(store) {
+		t.Fatal("the store path must not bypass the lock")
+	}
+
+	unlock, err := LockStore(store)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	env := lockEnv(store)
+	token := strings.TrimPrefix(env[len(env)-1], lockHeldEnv+"=")
+	t.Setenv(lockHeldEnv, token)
+	if !heldByParent(store) {
+		t.Fatal("a child of the holder should share its lock")
+	}
+
+	unlock()
+	if heldByParent(store) {
+		t.Fatal("the token must stop working once the lock is released")
+	}
+}
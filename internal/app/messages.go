@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// roleHeadingRe matches the '## system', '## user' and '## assistant'
+// headings that split a chat prompt into turns.
+var roleHeadingRe = regexp.MustCompile(`(?i)^##\s+(system|user|assistant)\s*$`)
+
+// ParseMessages splits rendered entry content into chat turns at role
+// headings. Headings inside fenced code blocks are ignored. Content without
+// any role heading is a single user turn.
+func ParseMessages(b []byte) ([]ChatMessage, error) {
+	var msgs []ChatMessage
+	var preamble []string
+	var cur []string
+	inFence := false
+	found := false
+
+	flush := func() {
+		if !found {
+			return
+		}
+		msgs[len(msgs)-1].Content = strings.Trim(strings.Join(cur, "\n"), "\n")
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if m := roleHeadingRe.FindStringSubmatch(line); m != nil && !inFence {
+			flush()
+			found = true
+			cur = nil
+			msgs = append(msgs, ChatMessage{Role: strings.ToLower(m[1])})
+			continue
+		}
+		if found {
+			cur = append(cur, line)
+		} else {
+			preamble = append(preamble, line)
+		}
+	}
+	flush()
+
+	text := strings.TrimSpace(strings.Join(preamble, "\n"))
+	if !found {
+		return []ChatMessage{{Role: "user", Content: strings.Trim(string(b), "\n")}}, nil
+	}
+	if text != "" {
+		return nil, fmt.Errorf("text before the first role heading: start the prompt with '## system' or '## user'")
+	}
+	return msgs, nil
+}
+
+// EntryMessages builds the chat messages for an entry from its rendered
+// content, adding the front matter 'system:' prompt unless the content has
+// its own system turn.
+func EntryMessages(entryFile, rendered []byte) ([]ChatMessage, error) {
+	msgs, err := ParseMessages(rendered)
+	if err != nil {
+		return nil, err
+	}
+	if system, ok := FrontMatterValue(entryFile, "system"); ok && system != "" && msgs[0].Role != "system" {
+		msgs = append([]ChatMessage{{Role: "system", Content: system}}, msgs...)
+	}
+	return msgs, nil
+}
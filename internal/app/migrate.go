@@ -0,0 +1,294 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Migration steps planned by PlanMigration.
+const (
+	MigrateRename    = "rename"    // name.txt becomes name.md
+	MigrateDrop      = "drop"      // name.txt duplicates name.md and is removed
+	MigrateMerge     = "merge"     // name.txt is merged into name.md
+	MigrateConflict  = "conflict"  // name.txt and name.md differ; left alone
+	MigrateNormalize = "normalize" // front matter of name.md is rewritten
+)
+
+// MigrateStep is one planned change to a legacy entry.
+type MigrateStep struct {
+	Name   string
+	Action string
+	// Content is what name.md will hold afterwards; empty for drop and
+	// conflict steps.
+	Content []byte
+}
+
+// String describes the step for plan output.
+func (s MigrateStep) String() string {
+	switch s.Action {
+	case MigrateRename:
+		return fmt.Sprintf("rename    %s%s -> %s%s", s.Name, LegacyExt, s.Name, DefaultExt)
+	case MigrateDrop:
+		return fmt.Sprintf("drop      %s%s (same as %s%s)", s.Name, LegacyExt, s.Name, DefaultExt)
+	case MigrateMerge:
+		return fmt.Sprintf("merge     %s%s into %s%s", s.Name, LegacyExt, s.Name, DefaultExt)
+	case MigrateConflict:
+		return fmt.Sprintf("conflict  %s%s differs from %s%s (use --merge or resolve by hand)", s.Name, LegacyExt, s.Name, DefaultExt)
+	case MigrateNormalize:
+		return fmt.Sprintf("normalize %s%s front matter", s.Name, DefaultExt)
+	}
+	return s.Action + " " + s.Name
+}
+
+// PlanMigration works out how to convert every legacy .txt entry to .md.
+// Pairs whose contents differ are merged when merge is set and reported as
+// conflicts otherwise. Front matter is normalized along the way, including
+// that of .md entries without a legacy twin.
+func PlanMigration(store string, merge bool) ([]MigrateStep, error) {
+	files, err := os.ReadDir(store)
+	if err != nil {
+		return nil, err
+	}
+	var names, mdOnly []string
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		switch name := f.Name(); {
+		case strings.HasSuffix(name, LegacyExt):
+			names = append(names, strings.TrimSuffix(name, LegacyExt))
+		case strings.HasSuffix(name, DefaultExt) && !FileExists(LegacyEntryPath(store, strings.TrimSuffix(name, DefaultExt))):
+			mdOnly = append(mdOnly, strings.TrimSuffix(name, DefaultExt))
+		}
+	}
+	sort.Strings(names)
+
+	var plan []MigrateStep
+	for _, name := range names {
+		txt, err := os.ReadFile(LegacyEntryPath(store, name))
+		if err != nil {
+			return nil, err
+		}
+		txt = normalizeEntry(txt)
+
+		md, err := os.ReadFile(DefaultEntryPath(store, name))
+		if os.IsNotExist(err) {
+			plan = append(plan, MigrateStep{Name: name, Action: MigrateRename, Content: txt})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		normalized := normalizeEntry(md)
+		switch {
+		case bytes.Equal(normalized, txt) || bytes.Equal(StripFrontMatter(normalized), txt):
+			plan = append(plan, MigrateStep{Name: name, Action: MigrateDrop})
+			if !bytes.Equal(normalized, md) {
+				plan = append(plan, MigrateStep{Name: name, Action: MigrateNormalize, Content: normalized})
+			}
+		case merge:
+			plan = append(plan, MigrateStep{Name: name, Action: MigrateMerge, Content: mergeEntries(normalized, txt)})
+		default:
+			plan = append(plan, MigrateStep{Name: name, Action: MigrateConflict})
+		}
+	}
+
+	for _, name := range mdOnly {
+		md, err := os.ReadFile(DefaultEntryPath(store, name))
+		if err != nil {
+			return nil, err
+		}
+		if normalized := normalizeEntry(md); !bytes.Equal(normalized, md) {
+			plan = append(plan, MigrateStep{Name: name, Action: MigrateNormalize, Content: normalized})
+		}
+	}
+	return plan, nil
+}
+
+// ApplyMigration carries out plan and records it as a single commit. All new
+// contents are written to staging files before any entry is touched, and if
+// moving them into place or committing fails the store is put back as it
+// was. It returns the number of legacy files removed.
+func ApplyMigration(store string, plan []MigrateStep, stderr io.Writer) (migrated int, err error) {
+	unlock, err := LockStore(store)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Hidden, extension-less names keep staged and set-aside files out of
+	// entry listings while the migration runs.
+	var scratch []string
+	defer func() {
+		for _, p := range scratch {
+			_ = os.Remove(p)
+		}
+	}()
+	staged := make(map[string]string)
+	for _, s := range plan {
+		if s.Content == nil {
+			continue
+		}
+		p := filepath.Join(store, "."+s.Name+DefaultExt+".migrate")
+		if err := WriteFileAtomic(p, s.Content, 0o644); err != nil {
+			return 0, fmt.Errorf("write %s%s: %w", s.Name, DefaultExt, err)
+		}
+		scratch = append(scratch, p)
+		staged[s.Name] = p
+	}
+
+	var undo []func()
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		migrated = 0
+	}()
+	// setAside moves path out of the way so undo can bring it back.
+	setAside := func(path string) error {
+		backup := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".orig")
+		if err := os.Rename(path, backup); err != nil {
+			return err
+		}
+		scratch = append(scratch, backup)
+		undo = append(undo, func() { _ = os.Rename(backup, path) })
+		return nil
+	}
+
+	var paths []string
+	for _, s := range plan {
+		if s.Action == MigrateConflict {
+			continue
+		}
+		if p, ok := staged[s.Name]; ok {
+			md := DefaultEntryPath(store, s.Name)
+			if FileExists(md) {
+				if err := setAside(md); err != nil {
+					return 0, fmt.Errorf("replace %s%s: %w", s.Name, DefaultExt, err)
+				}
+			}
+			if err := os.Rename(p, md); err != nil {
+				return 0, fmt.Errorf("replace %s%s: %w", s.Name, DefaultExt, err)
+			}
+			undo = append(undo, func() { _ = os.Remove(md) })
+			paths = append(paths, s.Name+DefaultExt)
+		}
+		if s.Action != MigrateNormalize {
+			if err := setAside(LegacyEntryPath(store, s.Name)); err != nil {
+				return 0, fmt.Errorf("remove %s%s: %w", s.Name, LegacyExt, err)
+			}
+			paths = append(paths, s.Name+LegacyExt)
+			migrated++
+		}
+	}
+
+	if len(paths) > 0 && hasGit(store) {
+		var stage []string
+		for _, p := range paths {
+			if FileExists(filepath.Join(store, p)) || IsTracked(store, p) {
+				stage = append(stage, p)
+			}
+		}
+		msg := fmt.Sprintf("chore: migrate %d legacy entries to %s", migrated, DefaultExt)
+		if migrated == 0 {
+			msg = fmt.Sprintf("chore: normalize front matter of %d entries", len(paths))
+		}
+		if err := CommitPaths(store, stage, msg, stderr); err != nil {
+			return 0, err
+		}
+	}
+	return migrated, nil
+}
+
+// normalizeEntry converts CRLF line endings and tidies front matter: keys
+// get a single space after the colon and trailing whitespace is removed.
+func normalizeEntry(b []byte) []byte {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	lines, ok := frontMatterLines(b)
+	if !ok {
+		return b
+	}
+	out := []string{"---"}
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if frontMatterKeyRe.MatchString(line) {
+			key, value, _ := strings.Cut(line, ":")
+			line = key + ":"
+			if value = strings.TrimSpace(value); value != "" {
+				line += " " + value
+			}
+		}
+		out = append(out, line)
+	}
+	out = append(out, "---")
+	return append([]byte(strings.Join(out, "\n")+"\n"), StripFrontMatter(b)...)
+}
+
+// frontMatterBlock is a top-level front matter key with its continuation
+// lines, such as the items of a block list.
+type frontMatterBlock struct {
+	key   string
+	lines []string
+}
+
+func frontMatterBlocks(lines []string) []frontMatterBlock {
+	var blocks []frontMatterBlock
+	for _, line := range lines {
+		if frontMatterKeyRe.MatchString(line) {
+			key, _, _ := strings.Cut(line, ":")
+			blocks = append(blocks, frontMatterBlock{key: key})
+		}
+		if len(blocks) == 0 {
+			blocks = append(blocks, frontMatterBlock{})
+		}
+		blocks[len(blocks)-1].lines = append(blocks[len(blocks)-1].lines, line)
+	}
+	return blocks
+}
+
+// mergeEntries combines a .md entry with its legacy .txt twin. The .md
+// front matter wins, keys only the .txt has are added, tags are combined,
+// and the .txt body is appended after the .md body.
+func mergeEntries(md, txt []byte) []byte {
+	mdLines, _ := frontMatterLines(md)
+	txtLines, _ := frontMatterLines(txt)
+	tags := uniqueTags(append(parseTags(md), parseTags(txt)...))
+
+	blocks := frontMatterBlocks(mdLines)
+	have := make(map[string]bool)
+	for _, b := range blocks {
+		have[b.key] = true
+	}
+	for _, b := range frontMatterBlocks(txtLines) {
+		if b.key != "" && !have[b.key] {
+			blocks = append(blocks, b)
+			have[b.key] = true
+		}
+	}
+
+	var fm []string
+	for _, b := range blocks {
+		if b.key == "tags" {
+			fm = append(fm, "tags: ["+strings.Join(tags, ", ")+"]")
+			continue
+		}
+		fm = append(fm, b.lines...)
+	}
+
+	body := strings.TrimRight(string(StripFrontMatter(md)), "\n") + "\n\n" + strings.TrimLeft(string(StripFrontMatter(txt)), "\n")
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	if len(fm) == 0 {
+		return []byte(body)
+	}
+	return []byte("---\n" + strings.Join(fm, "\n") + "\n---\n" + body)
+}
@@ -13,13 +13,48 @@ func NormalizeName(raw string) (string, error) {
 	if name == "" {
 		return "", fmt.Errorf("invalid name %q: use letters, numbers, or underscores", raw)
 	}
+	if isBuiltinReserved(name) {
+		return "", fmt.Errorf("invalid name %q: %q is a reserved command", raw, name)
+	}
+	return name, nil
+}
+
+// NormalizeNewName is NormalizeName for entries about to be created. It
+// also rejects every command and alias name: 'pea <name>' runs those
+// before looking up an entry, so the entry would be shadowed. Lookups keep
+// using NormalizeName so entries created before a command was added stay
+// readable with 'pea get'.
+func NormalizeNewName(raw string) (string, error) {
+	name, err := NormalizeName(raw)
+	if err != nil {
+		return "", err
+	}
 	if isReserved(name) {
 		return "", fmt.Errorf("invalid name %q: %q is a reserved command", raw, name)
 	}
+	if _, ok := ReadConfig().Aliases[name]; ok {
+		return "", fmt.Errorf("invalid name %q: %q is an alias", raw, name)
+	}
 	return name, nil
 }
 
+// commandNames holds the names and aliases of the CLI's commands, as
+// registered by SetCommandNames.
+var commandNames = map[string]bool{}
+
+// SetCommandNames registers the CLI's command names and aliases as
+// reserved for new entries.
+func SetCommandNames(names []string) {
+	for _, n := range names {
+		commandNames[n] = true
+	}
+}
+
 func isReserved(name string) bool {
+	return isBuiltinReserved(name) || commandNames[name]
+}
+
+func isBuiltinReserved(name string) bool {
 	switch name {
 	case "add", "ls", "rm", "mv", "history", "search", "completion", "help":
 		return true
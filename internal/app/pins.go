@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pinsFileName lists pinned entry names, one per line. It lives in the store
+// so favorites sync along with the entries.
+const pinsFileName = ".pins"
+
+func pinsPath(store string) string {
+	return filepath.Join(store, pinsFileName)
+}
+
+// LoadPins returns the set of pinned entry names.
+func LoadPins(store string) (map[string]bool, error) {
+	pins := make(map[string]bool)
+	b, err := os.ReadFile(pinsPath(store))
+	if os.IsNotExist(err) {
+		return pins, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			pins[name] = true
+		}
+	}
+	return pins, nil
+}
+
+// SetPinned pins or unpins an existing entry and commits the change. It
+// reports whether anything changed.
+func SetPinned(store, name string, pinned bool, stderr io.Writer) (bool, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return false, err
+	}
+	if pinned {
+		if _, _, err := ExistingEntryPath(store, name); err != nil {
+			return false, fmt.Errorf("not found: %s", name)
+		}
+	}
+
+	unlock, err := LockStore(store)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	pins, err := LoadPins(store)
+	if err != nil {
+		return false, err
+	}
+	if pins[name] == pinned {
+		return false, nil
+	}
+	if pinned {
+		pins[name] = true
+	} else {
+		delete(pins, name)
+	}
+	if err := writePins(store, pins); err != nil {
+		return false, err
+	}
+
+	verb := "pin"
+	if !pinned {
+		verb = "unpin"
+	}
+	GitAddAndCommit(store, []string{pinsFileName}, fmt.Sprintf("chore: %s %s", verb, name), stderr)
+	return true, nil
+}
+
+// PlanUnpin returns the pins file rewritten without names, for entries that
+// are being removed, or nil if none of them is pinned. Nothing is written.
+func PlanUnpin(store string, names []string) (*RefUpdate, error) {
+	pins, err := LoadPins(store)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, name := range names {
+		if pins[name] {
+			delete(pins, name)
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return &RefUpdate{Path: pinsPath(store), File: pinsFileName, Kind: RefPin, Count: n, Content: pinsContent(pins)}, nil
+}
+
+func writePins(store string, pins map[string]bool) error {
+	return WriteFileAtomic(pinsPath(store), pinsContent(pins), 0o644)
+}
+
+// pinsContent formats the pins file: sorted names, one per line.
+func pinsContent(pins map[string]bool) []byte {
+	var names []string
+	for n := range pins {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, n := range names {
+		b.WriteString(n + "\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,337 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Remote provider names accepted in config and by 'pea remote create --provider'.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea"
+	ProviderBare   = "bare"
+)
+
+// RemoteConfig is the [remote] config table used by 'pea remote create'.
+type RemoteConfig struct {
+	Provider string `toml:"provider,omitempty"`
+	URL      string `toml:"url,omitempty"`
+	Token    string `toml:"token,omitempty"`
+	Protocol string `toml:"protocol,omitempty"`
+	Path     string `toml:"path,omitempty"`
+}
+
+// RepoSpec describes a repository to create on a provider.
+type RepoSpec struct {
+	Name   string
+	Public bool
+}
+
+// RemoteProvider creates repositories on a hosting service.
+type RemoteProvider interface {
+	// Target describes where the repository will be created, for confirmation.
+	// It also verifies that the provider is usable (tools installed, logged in).
+	Target(spec RepoSpec) (string, error)
+	// Create creates the repository and returns the URL to use as origin.
+	Create(store string, spec RepoSpec, stdout, stderr io.Writer) (string, error)
+}
+
+// NewRemoteProvider returns the provider named by name, falling back to the
+// configured provider and then GitHub.
+func NewRemoteProvider(name string, conf RemoteConfig) (RemoteProvider, error) {
+	if name == "" {
+		name = conf.Provider
+	}
+	token := conf.Token
+	if v := os.Getenv("PEA_REMOTE_TOKEN"); v != "" {
+		token = v
+	}
+
+	switch name {
+	case "", ProviderGitHub:
+		return &githubProvider{}, nil
+	case ProviderGitLab:
+		if token == "" {
+			token = os.Getenv("GITLAB_TOKEN")
+		}
+		base := conf.URL
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+		return &gitlabProvider{api: newAPIClient(base, token), ssh: conf.Protocol != "https"}, nil
+	case ProviderGitea:
+		if token == "" {
+			token = os.Getenv("GITEA_TOKEN")
+		}
+		if conf.URL == "" {
+			return nil, fmt.Errorf("gitea provider requires url in the [remote] config")
+		}
+		if token == "" {
+			return nil, fmt.Errorf("gitea provider requires a token (config [remote] token, PEA_REMOTE_TOKEN or GITEA_TOKEN)")
+		}
+		return &giteaProvider{api: newAPIClient(conf.URL, token), ssh: conf.Protocol != "https"}, nil
+	case ProviderBare:
+		if conf.Path == "" {
+			return nil, fmt.Errorf("bare provider requires path in the [remote] config (local directory or ssh://host/dir)")
+		}
+		return &bareProvider{base: conf.Path}, nil
+	}
+	return nil, fmt.Errorf("unknown remote provider %q: use %s, %s, %s or %s", name, ProviderGitHub, ProviderGitLab, ProviderGitea, ProviderBare)
+}
+
+func visibility(public bool) string {
+	if public {
+		return "public"
+	}
+	return "private"
+}
+
+// githubProvider creates repositories with the GitHub CLI.
+type githubProvider struct{}
+
+// PushesOnCreate reports whether p's Create already sets the store's origin
+// and pushes to it, as 'gh repo create --push' does, so callers must not
+// link and push again.
+func PushesOnCreate(p RemoteProvider) bool {
+	_, ok := p.(*githubProvider)
+	return ok
+}
+
+func (p *githubProvider) Target(spec RepoSpec) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("GitHub CLI 'gh' not found. Please install it: https://cli.github.com/")
+	}
+	out, err := exec.Command("gh", "api", "user", "--jq", ".login").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to check GitHub login status. Run 'gh auth login' first.\nError: %w", err)
+	}
+	user := strings.TrimSpace(string(out))
+	if user == "" {
+		return "", fmt.Errorf("not logged in to GitHub. Run 'gh auth login' first")
+	}
+	return fmt.Sprintf("github.com/%s/%s", user, spec.Name), nil
+}
+
+func (p *githubProvider) Create(store string, spec RepoSpec, stdout, stderr io.Writer) (string, error) {
+	// gh repo create <name> --private --source=. --remote=origin --push
+	ghArgs := []string{
+		"repo", "create", spec.Name,
+		"--source=.",
+		"--remote=origin",
+		"--push",
+		"--" + visibility(spec.Public),
+	}
+	c := exec.Command("gh", ghArgs...)
+	c.Dir = store
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("failed to create repository: %w", err)
+	}
+	return RemoteURL(store), nil
+}
+
+// apiClient is a minimal JSON client for provider REST APIs.
+type apiClient struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+func newAPIClient(base, token string) *apiClient {
+	return &apiClient{
+		base:  strings.TrimRight(base, "/"),
+		token: token,
+		http:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *apiClient) host() string {
+	if u, err := url.Parse(c.base); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return c.base
+}
+
+func (c *apiClient) postJSON(path string, headers map[string]string, body, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.base+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", c.host(), err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s: %s", c.host(), resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
+
+// gitlabProvider creates projects through the GitLab REST API, or through
+// 'glab api' (which handles authentication) when no token is configured.
+type gitlabProvider struct {
+	api *apiClient
+	ssh bool
+}
+
+type gitlabProject struct {
+	SSHURL  string `json:"ssh_url_to_repo"`
+	HTTPURL string `json:"http_url_to_repo"`
+}
+
+func (p *gitlabProvider) Target(spec RepoSpec) (string, error) {
+	if p.api.token == "" {
+		if _, err := exec.LookPath("glab"); err != nil {
+			return "", fmt.Errorf("gitlab provider requires a token (config [remote] token, PEA_REMOTE_TOKEN or GITLAB_TOKEN) or the 'glab' CLI")
+		}
+	}
+	return p.api.host() + "/" + spec.Name, nil
+}
+
+func (p *gitlabProvider) Create(_ string, spec RepoSpec, _, _ io.Writer) (string, error) {
+	var project gitlabProject
+	if p.api.token != "" {
+		body := map[string]string{"name": spec.Name, "visibility": visibility(spec.Public)}
+		headers := map[string]string{"PRIVATE-TOKEN": p.api.token}
+		if err := p.api.postJSON("/api/v4/projects", headers, body, &project); err != nil {
+			return "", fmt.Errorf("failed to create repository: %w", err)
+		}
+	} else {
+		out, err := exec.Command("glab", "api", "projects", "-X", "POST",
+			"-f", "name="+spec.Name, "-f", "visibility="+visibility(spec.Public)).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to create repository with glab: %w", err)
+		}
+		if err := json.Unmarshal(out, &project); err != nil {
+			return "", fmt.Errorf("unexpected glab output: %w", err)
+		}
+	}
+	return pickCloneURL(p.ssh, project.SSHURL, project.HTTPURL)
+}
+
+// giteaProvider creates repositories through the Gitea/Forgejo REST API.
+type giteaProvider struct {
+	api *apiClient
+	ssh bool
+}
+
+type giteaRepo struct {
+	SSHURL   string `json:"ssh_url"`
+	CloneURL string `json:"clone_url"`
+}
+
+func (p *giteaProvider) Target(spec RepoSpec) (string, error) {
+	return p.api.host() + "/" + spec.Name, nil
+}
+
+func (p *giteaProvider) Create(_ string, spec RepoSpec, _, _ io.Writer) (string, error) {
+	var repo giteaRepo
+	body := map[string]any{"name": spec.Name, "private": !spec.Public}
+	headers := map[string]string{"Authorization": "token " + p.api.token}
+	if err := p.api.postJSON("/api/v1/user/repos", headers, body, &repo); err != nil {
+		return "", fmt.Errorf("failed to create repository: %w", err)
+	}
+	return pickCloneURL(p.ssh, repo.SSHURL, repo.CloneURL)
+}
+
+func pickCloneURL(preferSSH bool, sshURL, httpURL string) (string, error) {
+	if preferSSH && sshURL != "" {
+		return sshURL, nil
+	}
+	if httpURL != "" {
+		return httpURL, nil
+	}
+	if sshURL != "" {
+		return sshURL, nil
+	}
+	return "", fmt.Errorf("provider response did not include a clone URL")
+}
+
+// bareProvider creates bare repositories in a local directory or over SSH.
+type bareProvider struct {
+	base string
+}
+
+func (p *bareProvider) repoURL(name string) string {
+	return strings.TrimRight(p.base, "/") + "/" + name + ".git"
+}
+
+func (p *bareProvider) Target(spec RepoSpec) (string, error) {
+	return p.repoURL(spec.Name), nil
+}
+
+func (p *bareProvider) Create(_ string, spec RepoSpec, stdout, stderr io.Writer) (string, error) {
+	repo := p.repoURL(spec.Name)
+
+	var c *exec.Cmd
+	if host, port, dir, ok := parseSSHRemote(repo); ok {
+		sshArgs := []string{}
+		if port != "" {
+			sshArgs = append(sshArgs, "-p", port)
+		}
+		sshArgs = append(sshArgs, host, "git", "init", "--bare", "--quiet", dir)
+		c = exec.Command("ssh", sshArgs...)
+	} else {
+		if !filepath.IsAbs(repo) {
+			return "", fmt.Errorf("bare provider path must be absolute or an ssh remote, got %q", p.base)
+		}
+		if _, err := os.Stat(repo); err == nil {
+			return "", fmt.Errorf("repository already exists: %s", repo)
+		}
+		c = exec.Command("git", "init", "--bare", "--quiet", repo)
+	}
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("failed to create repository %s: %w", repo, err)
+	}
+	return repo, nil
+}
+
+// parseSSHRemote splits ssh://[user@]host[:port]/dir and scp-style
+// [user@]host:dir remotes into the pieces needed to run ssh.
+func parseSSHRemote(remote string) (host, port, dir string, ok bool) {
+	if strings.HasPrefix(remote, "ssh://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Host == "" {
+			return "", "", "", false
+		}
+		host = u.Hostname()
+		if u.User != nil {
+			host = u.User.Username() + "@" + host
+		}
+		return host, u.Port(), u.Path, true
+	}
+	if filepath.IsAbs(remote) {
+		return "", "", "", false
+	}
+	if i := strings.Index(remote, ":"); i > 0 && !strings.Contains(remote[:i], "/") {
+		return remote[:i], "", remote[i+1:], true
+	}
+	return "", "", "", false
+}
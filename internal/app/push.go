@@ -0,0 +1,127 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Push modes control when commits made by pea are pushed to the remote.
+const (
+	PushImmediate = "immediate"
+	PushBatched   = "batched"
+	PushManual    = "manual"
+
+	DefaultPushBatch = 5
+)
+
+func validatePushMode(mode string) error {
+	switch mode {
+	case "", PushImmediate, PushBatched, PushManual:
+		return nil
+	}
+	return fmt.Errorf("push must be one of %q, %q or %q, got %q", PushImmediate, PushBatched, PushManual, mode)
+}
+
+// GetPushConfig returns the configured push mode and batch size.
+// PEA_PUSH overrides the mode from the config file.
+func GetPushConfig() (string, int) {
+	conf := ReadConfig()
+	mode := conf.Push
+	if v := os.Getenv("PEA_PUSH"); v != "" {
+		mode = v
+	}
+	if validatePushMode(mode) != nil || mode == "" {
+		mode = PushImmediate
+	}
+	batch := conf.PushBatch
+	if batch <= 0 {
+		batch = DefaultPushBatch
+	}
+	return mode, batch
+}
+
+// HasRemote reports whether the store has an origin remote configured.
+func HasRemote(store string) bool {
+	if !hasGit(store) {
+		return false
+	}
+	_, err := runGit(store, "config", "--get", "remote.origin.url")
+	return err == nil
+}
+
+// RemoteURL returns the origin URL of the store, or "" if none is configured.
+func RemoteURL(store string) string {
+	out, err := runGit(store, "config", "--get", "remote.origin.url")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// UnpushedCommits lists local commits (as "<short sha> <subject>") that are
+// not yet on any origin remote-tracking branch, newest first.
+func UnpushedCommits(store string) ([]string, error) {
+	if !hasGit(store) {
+		return nil, fmt.Errorf("git is not enabled")
+	}
+	if _, err := runGit(store, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// No commits yet
+		return nil, nil
+	}
+
+	out, err := runGit(store, "log", "--format=%h %s", "HEAD", "--not", "--remotes=origin")
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w: %s", err, string(out))
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// PushAfterCommit pushes according to the configured push mode. Immediate
+// mode syncs in the foreground, batched mode starts a background push once
+// enough commits are queued, and manual mode leaves pushing to 'pea sync'.
+// The push waits until this process releases the store lock.
+func PushAfterCommit(store string, stderr io.Writer) {
+	if !HasRemote(store) {
+		return
+	}
+	afterUnlock(store, func() { pushAfterCommit(store, stderr) })
+}
+
+func pushAfterCommit(store string, stderr io.Writer) {
+	mode, batch := GetPushConfig()
+	switch mode {
+	case PushManual:
+		return
+	case PushBatched:
+		pending, err := UnpushedCommits(store)
+		if err != nil || len(pending) < batch {
+			return
+		}
+		if err := startBackgroundPush(store); err != nil {
+			fmt.Fprintf(stderr, "warning: background push failed to start: %v\n", err)
+		}
+	default:
+		PushIfRemote(store, stderr)
+	}
+}
+
+// startBackgroundPush launches a detached git push that outlives the current
+// process. Failures leave the commits queued for the next flush or 'pea sync'.
+func startBackgroundPush(store string) error {
+	c := exec.Command("git", "push", "-u", "origin", "HEAD")
+	c.Dir = store
+	c.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Process.Release()
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Kinds of references PlanRenameRefs rewrites.
+const (
+	RefInclude    = "include"
+	RefCollection = "collection member"
+	RefPin        = "pin"
+	RefAlias      = "alias"
+)
+
+// RefUpdate is a file rewritten because an entry it references was renamed.
+type RefUpdate struct {
+	Path    string // absolute path of the file
+	File    string // path relative to the store; empty for the config file
+	Kind    string
+	Count   int // number of references rewritten
+	Content []byte
+}
+
+// Describe summarizes the update, e.g. "review.md (2 includes)".
+func (u RefUpdate) Describe() string {
+	name := u.File
+	if name == "" {
+		name = filepath.Base(u.Path)
+	}
+	kind := u.Kind
+	if u.Count != 1 {
+		if strings.HasSuffix(kind, "s") {
+			kind += "e"
+		}
+		kind += "s"
+	}
+	return fmt.Sprintf("%s (%d %s)", name, u.Count, kind)
+}
+
+// PlanRenameRefs finds everything that refers to the entries being renamed,
+// given as old name to new name, and returns the rewritten files that point
+// at the new names instead: include directives, collection members, the pins
+// file and aliases in the config. Renamed entries that refer to each other
+// are planned at their new paths, as they will be once every rename is done.
+// Includes pinned to a revision keep the old name, which is what the entry
+// was called at that revision. Nothing is written.
+func PlanRenameRefs(store string, renames map[string]string) ([]RefUpdate, error) {
+	names, err := ListEntries(store)
+	if err != nil {
+		return nil, err
+	}
+	var updates []RefUpdate
+	for _, name := range names {
+		path, ext, err := ExistingEntryPath(store, name)
+		if err != nil {
+			return nil, err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		kind := RefInclude
+		rename := renameIncludes
+		if IsCollection(b) {
+			kind = RefCollection
+			rename = renameCollectionMember
+		}
+		out, n := b, 0
+		for oldName, newName := range renames {
+			var c int
+			out, c = rename(out, oldName, newName)
+			n += c
+		}
+		if n == 0 {
+			continue
+		}
+		if newName, ok := renames[name]; ok {
+			name = newName
+			path = filepath.Join(filepath.Dir(path), name+ext)
+		}
+		updates = append(updates, RefUpdate{Path: path, File: name + ext, Kind: kind, Count: n, Content: out})
+	}
+
+	pins, err := LoadPins(store)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for oldName, newName := range renames {
+		if pins[oldName] {
+			delete(pins, oldName)
+			pins[newName] = true
+			n++
+		}
+	}
+	if n > 0 {
+		updates = append(updates, RefUpdate{Path: pinsPath(store), File: pinsFileName, Kind: RefPin, Count: n, Content: pinsContent(pins)})
+	}
+
+	base, _ := DefaultPaths()
+	cfgPath := filepath.Join(base, "config.toml")
+	if b, err := os.ReadFile(cfgPath); err == nil {
+		out, n := b, 0
+		for oldName, newName := range renames {
+			var c int
+			out, c = renameAliasRefs(out, oldName, newName)
+			n += c
+		}
+		if n > 0 {
+			updates = append(updates, RefUpdate{Path: cfgPath, Kind: RefAlias, Count: n, Content: out})
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// renameIncludes rewrites unpinned include directives naming oldName.
+func renameIncludes(b []byte, oldName, newName string) ([]byte, int) {
+	n := 0
+	out := includeRe.ReplaceAllFunc(b, func(m []byte) []byte {
+		sub := includeRe.FindSubmatch(m)
+		if len(sub[2]) > 0 {
+			return m
+		}
+		if name, err := NormalizeName(string(sub[1])); err != nil || name != oldName {
+			return m
+		}
+		n++
+		return []byte(strings.Replace(string(m), string(sub[1]), newName, 1))
+	})
+	return out, n
+}
+
+// renameCollectionMember rewrites the member lines of collection file b
+// that name oldName, keeping their list markers and variables.
+func renameCollectionMember(b []byte, oldName, newName string) ([]byte, int) {
+	body := StripFrontMatter(b)
+	head := string(b[:len(b)-len(body)])
+	lines := strings.Split(string(body), "\n")
+	n := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		words, err := SplitArgs(trimmed)
+		if err != nil || len(words) == 0 {
+			continue
+		}
+		if name, err := NormalizeName(words[0]); err != nil || name != oldName {
+			continue
+		}
+		lines[i] = strings.Replace(line, words[0], newName, 1)
+		n++
+	}
+	return []byte(head + strings.Join(lines, "\n")), n
+}
+
+// renameAliasRefs rewrites oldName where it is an entry argument in the
+// values of the config's [aliases] table, leaving the rest of the file
+// untouched. Command names, flags and flag values are kept, so renaming
+// "text" leaves 'get foo --format text' alone.
+func renameAliasRefs(b []byte, oldName, newName string) ([]byte, int) {
+	lines := strings.Split(string(b), "\n")
+	inAliases := false
+	n := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inAliases = trimmed == "[aliases]"
+			continue
+		}
+		if !inAliases || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		var v struct{ Alias string }
+		if _, err := toml.Decode("alias ="+value, &v); err != nil {
+			continue
+		}
+		words, err := SplitArgs(v.Alias)
+		if err != nil {
+			continue
+		}
+		c := 0
+		for j, w := range words {
+			if w == "--" {
+				break
+			}
+			if w != oldName || isReserved(w) {
+				continue
+			}
+			// A word after a flag without '=' is taken as its value.
+			if j > 0 && strings.HasPrefix(words[j-1], "-") && !strings.Contains(words[j-1], "=") {
+				continue
+			}
+			words[j] = newName
+			c++
+		}
+		if c > 0 {
+			lines[i] = key + "= " + tomlString(JoinArgs(words))
+			n += c
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), n
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(map[string]string{"s": s}); err != nil {
+		return strconv.Quote(s)
+	}
+	_, v, _ := strings.Cut(strings.TrimSpace(buf.String()), "=")
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveRunner picks the command 'pea run' feeds an entry to: the runner
+// named by the --runner flag, else the entry's 'runner:' front matter, else
+// the config's default 'runner'. Runners are defined in the [runners] table.
+func ResolveRunner(entryFile []byte, flagRunner string) (string, error) {
+	conf := ReadConfig()
+	name := flagRunner
+	if name == "" {
+		name, _ = FrontMatterValue(entryFile, "runner")
+	}
+	if name == "" {
+		name = conf.Runner
+	}
+	if name == "" {
+		return "", fmt.Errorf("no runner configured: set 'runner' and a [runners] table in the config, or pass a command after --")
+	}
+	command, ok := conf.Runners[name]
+	if !ok || strings.TrimSpace(command) == "" {
+		var known []string
+		for k := range conf.Runners {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		if len(known) == 0 {
+			return "", fmt.Errorf("unknown runner %q: no [runners] are configured", name)
+		}
+		return "", fmt.Errorf("unknown runner %q: use one of %s", name, strings.Join(known, ", "))
+	}
+	return command, nil
+}
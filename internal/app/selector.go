@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Selector picks entries for bulk operations. Entries must match one of the
+// patterns (if any), carry all tags and contain the query; at least one
+// criterion must be set.
+type Selector struct {
+	Patterns []string // entry names or globs such as draft_*
+	Tags     []string
+	Query    string // case-insensitive substring of the name or content, as in 'pea search'
+}
+
+// IsEmpty reports whether the selector has no criteria.
+func (s Selector) IsEmpty() bool {
+	return len(s.Patterns) == 0 && len(s.Tags) == 0 && s.Query == ""
+}
+
+// IsGlob reports whether a pattern contains glob characters.
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// SelectEntries returns the sorted names of the entries matching s. Plain
+// names must exist; globs may match nothing.
+func SelectEntries(store string, s Selector) ([]string, error) {
+	if s.IsEmpty() {
+		return nil, fmt.Errorf("no entries selected: give names, globs, --tag or --query")
+	}
+	var globs []string
+	names := make(map[string]bool)
+	for _, p := range s.Patterns {
+		if IsGlob(p) {
+			p = strings.ToLower(p)
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+			globs = append(globs, p)
+			continue
+		}
+		name, err := NormalizeName(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := ExistingEntryPath(store, name); err != nil {
+			return nil, fmt.Errorf("not found: %s", name)
+		}
+		names[name] = true
+	}
+
+	entries, err := CollectEntriesWithTags(store)
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(s.Query)
+	var out []string
+	for _, e := range entries {
+		if len(s.Patterns) > 0 && !names[e.Name] && !matchesAny(globs, e.Name) {
+			continue
+		}
+		if !HasAllTags(e.Tags, s.Tags) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(e.Name), query) && !strings.Contains(strings.ToLower(e.Content), query) {
+			continue
+		}
+		out = append(out, e.Name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func matchesAny(globs []string, name string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
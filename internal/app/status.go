@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Entry change states reported by StoreStatus.
+const (
+	ChangeModified  = "modified"
+	ChangeUntracked = "untracked"
+	ChangeDeleted   = "deleted"
+	ChangeAdded     = "added"
+	ChangeRenamed   = "renamed"
+)
+
+// EntryChange is an uncommitted change to an entry file.
+type EntryChange struct {
+	Name  string
+	File  string
+	State string
+}
+
+// StoreStatus describes uncommitted entry changes and how the local branch
+// relates to origin. Other holds changes to files that are not entries, such
+// as tests files and .pins, with Name left empty. Unpulled is only as fresh
+// as the last fetch.
+type StoreStatus struct {
+	Changes  []EntryChange
+	Other    []EntryChange
+	Unpushed []string
+	Unpulled []string
+}
+
+// GetStoreStatus collects the git status of entries in the store.
+func GetStoreStatus(store string) (StoreStatus, error) {
+	var st StoreStatus
+	if !hasGit(store) {
+		return st, fmt.Errorf("git is not enabled")
+	}
+
+	// -z leaves paths unquoted and gives renames and copies as a second
+	// record holding the original path, after the new one.
+	out, err := runGit(store, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return st, fmt.Errorf("git status failed: %w: %s", err, string(out))
+	}
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		if len(rec) < 4 {
+			continue
+		}
+		code, file := rec[:2], rec[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			i++ // skip the original path
+		}
+		state := changeState(code)
+		name, ok := entryNameFromFile(file)
+		if !ok {
+			st.Other = append(st.Other, EntryChange{File: file, State: state})
+			continue
+		}
+		st.Changes = append(st.Changes, EntryChange{Name: name, File: file, State: state})
+	}
+
+	if HasRemote(store) {
+		if st.Unpushed, err = UnpushedCommits(store); err != nil {
+			return st, err
+		}
+		if st.Unpulled, err = unpulledCommits(store); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+func changeState(code string) string {
+	switch {
+	case code == "??":
+		return ChangeUntracked
+	case strings.ContainsRune(code, 'D'):
+		return ChangeDeleted
+	case code[0] == 'R':
+		return ChangeRenamed
+	case code[0] == 'A':
+		return ChangeAdded
+	default:
+		return ChangeModified
+	}
+}
+
+// entryNameFromFile maps a store-relative path to an entry name, rejecting
+// files that are not entries.
+func entryNameFromFile(file string) (string, bool) {
+	if strings.Contains(file, "/") {
+		return "", false
+	}
+	for _, ext := range []string{DefaultExt, LegacyExt} {
+		if strings.HasSuffix(file, ext) {
+			return strings.TrimSuffix(file, ext), true
+		}
+	}
+	return "", false
+}
+
+// unpulledCommits lists commits on the upstream branch missing from HEAD.
+func unpulledCommits(store string) ([]string, error) {
+	if _, err := runGit(store, "rev-parse", "--verify", "--quiet", "@{u}"); err != nil {
+		// No upstream yet (nothing pushed or fetched)
+		return nil, nil
+	}
+	out, err := runGit(store, "log", "--format=%h %s", "@{u}", "--not", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w: %s", err, string(out))
+	}
+	var commits []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
+// Fetch updates origin's remote-tracking branches without touching the
+// working tree.
+func Fetch(store string) error {
+	if !HasRemote(store) {
+		return nil
+	}
+	if out, err := runGit(store, "fetch", "--quiet", "origin"); err != nil {
+		return fmt.Errorf("git fetch failed: %w: %s", err, string(out))
+	}
+	return nil
+}
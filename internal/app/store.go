@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -42,12 +41,25 @@ func ListEntries(store string) ([]string, error) {
 }
 
 // ReadEntry reads the content of an entry, optionally at a specific git revision.
-// It strips YAML front matter.
+// It strips YAML front matter and expands include directives ({{> name}}).
 func ReadEntry(store, name, rev string) ([]byte, error) {
 	name, err := NormalizeName(name)
 	if err != nil {
 		return nil, err
 	}
+	b, err := ReadEntryRaw(store, name, rev)
+	if err != nil {
+		return nil, err
+	}
+	return expandIncludes(store, b, rev, []string{includeKey(name, rev)})
+}
+
+// ReadEntryRaw is like ReadEntry but leaves include directives unexpanded.
+func ReadEntryRaw(store, name, rev string) ([]byte, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
 
 	if rev == "" {
 		path, _, err := ExistingEntryPath(store, name)
@@ -64,23 +76,82 @@ func ReadEntry(store, name, rev string) ([]byte, error) {
 		return StripFrontMatter(b), nil
 	}
 
-	if b, err := ShowAtRef(store, rev, name+DefaultExt); err == nil {
-		return StripFrontMatter(b), nil
+	b, err := showEntryAt(store, name, rev)
+	if err != nil {
+		return nil, err
 	}
-	if b, err := ShowAtRef(store, rev, name+LegacyExt); err == nil {
-		return StripFrontMatter(b), nil
+	return StripFrontMatter(b), nil
+}
+
+// ReadEntryFile returns the raw content of an entry, including front matter.
+func ReadEntryFile(store, name string) ([]byte, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	path, _, err := ExistingEntryPath(store, name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("not found: %s", name)
+		}
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// ReadEntryFileAt is ReadEntryFile at a git revision; an empty rev reads
+// the working tree.
+func ReadEntryFileAt(store, name, rev string) ([]byte, error) {
+	if rev == "" {
+		return ReadEntryFile(store, name)
+	}
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return showEntryAt(store, name, rev)
+}
+
+// showEntryAt returns the file of entry name at rev, trying both extensions.
+func showEntryAt(store, name, rev string) ([]byte, error) {
+	commit, err := ResolveRev(store, rev)
+	if errors.Is(err, errUnknownRev) {
+		return nil, fmt.Errorf("not found in ref %s: %s", rev, name)
+	} else if err != nil {
+		return nil, err
+	}
+	if b, err := ShowAtRef(store, commit, name+DefaultExt); err == nil {
+		return b, nil
+	}
+	if b, err := ShowAtRef(store, commit, name+LegacyExt); err == nil {
+		return b, nil
 	}
 	return nil, fmt.Errorf("not found in ref %s: %s", rev, name)
 }
 
+var errUnknownRev = errors.New("unknown revision")
+
+// ResolveRev resolves rev to a commit id. Revisions come from the command
+// line and from include directives in entry content, so anything git could
+// take for an option is rejected.
+func ResolveRev(store, rev string) (string, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", rev)
+	}
+	out, err := runGit(store, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w %q", errUnknownRev, rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ShowAtRef returns the content of path, relative to the store, at rev.
 func ShowAtRef(store, rev, path string) ([]byte, error) {
-	c := exec.Command("git", "show", fmt.Sprintf("%s:%s", rev, path))
-	c.Dir = store
-	out, err := c.CombinedOutput()
+	commit, err := ResolveRev(store, rev)
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return runGit(store, "show", commit+":"+path)
 }
 
 // StripFrontMatter removes simple YAML front matter delimited by lines starting with '---'.
@@ -143,6 +214,27 @@ func CollectEntriesWithTags(store string) ([]EntryWithTags, error) {
 	return entries, nil
 }
 
+// AllTags returns every tag used across the store with the number of entries
+// carrying it. Tags are matched case-insensitively; the first spelling wins.
+func AllTags(store string) (map[string]int, error) {
+	entries, err := CollectEntriesWithTags(store)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	spelling := make(map[string]string)
+	for _, e := range entries {
+		for _, t := range e.Tags {
+			lower := strings.ToLower(t)
+			if _, ok := spelling[lower]; !ok {
+				spelling[lower] = t
+			}
+			counts[spelling[lower]]++
+		}
+	}
+	return counts, nil
+}
+
 func HasAllTags(entryTags, required []string) bool {
 	if len(required) == 0 {
 		return true
@@ -185,7 +277,7 @@ func parseTags(b []byte) []string {
 					if next == "" {
 						continue
 					}
-					if strings.HasPrefix(next, "-") {
+					if strings.HasPrefix(next, "-") && next != "---" {
 						tag := normalizeTag(strings.TrimSpace(strings.TrimPrefix(next, "-")))
 						if tag != "" {
 							tags = append(tags, tag)
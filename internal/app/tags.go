@@ -0,0 +1,164 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// EditTags rewrites the 'tags:' front matter of entry file b to the result
+// of edit, keeping its style: an inline list stays inline, with or without
+// brackets, and a block list stays a block with the same indentation.
+// Entries without tags get an inline list, and front matter if needed; a
+// list edited down to nothing is removed. It reports whether b changed.
+func EditTags(b []byte, edit func(tags []string) []string) ([]byte, bool) {
+	old := parseTags(b)
+	tags := uniqueTags(edit(slices.Clone(old)))
+	if slices.Equal(old, tags) {
+		return b, false
+	}
+
+	lines := strings.Split(string(b), "\n")
+	fm, ok := frontMatterLines(b)
+	if !ok {
+		head := []string{"---", "tags: " + inlineTags(tags, true), "---"}
+		return []byte(strings.Join(append(head, lines...), "\n")), true
+	}
+	end := len(fm) + 1 // index of the closing '---'
+
+	key := -1
+	for i := 1; i < end; i++ {
+		if strings.HasPrefix(lines[i], "tags:") {
+			key = i
+			break
+		}
+	}
+	if key < 0 {
+		lines = slices.Insert(lines, end, "tags: "+inlineTags(tags, true))
+		return []byte(strings.Join(lines, "\n")), true
+	}
+
+	if value := strings.TrimSpace(strings.TrimPrefix(lines[key], "tags:")); value != "" {
+		if len(tags) == 0 {
+			lines = slices.Delete(lines, key, key+1)
+		} else {
+			lines[key] = "tags: " + inlineTags(tags, strings.HasPrefix(value, "["))
+		}
+		return joinDroppingEmptyFrontMatter(lines), true
+	}
+
+	// Block list: replace the '- item' lines following the key.
+	last := key
+	prefix := "  - "
+	for j := key + 1; j < end; j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "-") {
+			break
+		}
+		if last == key {
+			indent := lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " \t"))]
+			prefix = indent + "- "
+		}
+		last = j
+	}
+	items := make([]string, 0, len(tags))
+	for _, t := range tags {
+		items = append(items, prefix+t)
+	}
+	if len(tags) == 0 {
+		lines = slices.Delete(lines, key, last+1)
+	} else {
+		lines = slices.Replace(lines, key+1, last+1, items...)
+	}
+	return joinDroppingEmptyFrontMatter(lines), true
+}
+
+// joinDroppingEmptyFrontMatter joins lines, leaving out a front matter
+// block that removing the tags emptied.
+func joinDroppingEmptyFrontMatter(lines []string) []byte {
+	if len(lines) >= 2 && strings.TrimSpace(lines[0]) == "---" && strings.TrimSpace(lines[1]) == "---" {
+		lines = lines[2:]
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func inlineTags(tags []string, brackets bool) string {
+	s := strings.Join(tags, ", ")
+	if brackets {
+		return "[" + s + "]"
+	}
+	return s
+}
+
+// AddTags returns tags with the missing ones from add appended.
+func AddTags(tags, add []string) []string {
+	for _, t := range add {
+		if !HasAllTags(tags, []string{t}) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// RemoveTags returns tags without those in remove, ignoring case.
+func RemoveTags(tags, remove []string) []string {
+	return slices.DeleteFunc(tags, func(t string) bool {
+		return HasAllTags(remove, []string{t})
+	})
+}
+
+// TagUpdate is an entry file rewritten by a tag edit.
+type TagUpdate struct {
+	Name    string
+	File    string // relative to the store
+	Tags    []string
+	Content []byte
+}
+
+// PlanTagEdit applies edit to the tags of each named entry and returns the
+// entries whose file would change. Nothing is written.
+func PlanTagEdit(store string, names []string, edit func(tags []string) []string) ([]TagUpdate, error) {
+	var updates []TagUpdate
+	for _, name := range names {
+		path, ext, err := ExistingEntryPath(store, name)
+		if err != nil {
+			return nil, fmt.Errorf("not found: %s", name)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if out, changed := EditTags(b, edit); changed {
+			updates = append(updates, TagUpdate{Name: name, File: name + ext, Tags: parseTags(out), Content: out})
+		}
+	}
+	return updates, nil
+}
+
+// ParseTagArgs validates tags given on the command line.
+func ParseTagArgs(args []string) ([]string, error) {
+	var tags []string
+	for _, a := range args {
+		t := normalizeTag(a)
+		if t == "" || strings.ContainsAny(t, ",[]#:") || strings.ContainsAny(t, " \t") {
+			return nil, fmt.Errorf("invalid tag %q: use a single word without commas, brackets, '#' or ':'", a)
+		}
+		tags = append(tags, t)
+	}
+	return uniqueTags(tags), nil
+}
+
+// RenameTag returns tags with oldTag, matched ignoring case, replaced by
+// newTag in place.
+func RenameTag(tags []string, oldTag, newTag string) []string {
+	for i, t := range tags {
+		if strings.EqualFold(t, oldTag) {
+			tags[i] = newTag
+		}
+	}
+	return tags
+}
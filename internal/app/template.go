@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateVarRe matches template placeholders such as {{lang}}. Include
+// directives ({{> name}}) do not match.
+var templateVarRe = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVar is a variable declared under 'vars:' in an entry's front matter.
+type TemplateVar struct {
+	Name       string
+	Default    string
+	HasDefault bool
+}
+
+// TemplateVars returns the names of the placeholders used in b, in order of
+// first appearance.
+func TemplateVars(b []byte) []string {
+	var out []string
+	seen := make(map[string]struct{})
+	for _, m := range templateVarRe.FindAllSubmatch(b, -1) {
+		name := string(m[1])
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	return out
+}
+
+// DeclaredVars returns the variables declared in the entry's front matter,
+// either as an inline list (vars: [lang, topic]) or as a block of names
+// with optional defaults:
+//
+//	vars:
+//	  lang: go
+//	  topic:
+func DeclaredVars(b []byte) []TemplateVar {
+	lines, ok := frontMatterLines(b)
+	if !ok {
+		return nil
+	}
+	var vars []TemplateVar
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "vars:") {
+			continue
+		}
+		if value := strings.TrimSpace(strings.TrimPrefix(line, "vars:")); value != "" {
+			for _, name := range splitInlineTagList(value) {
+				vars = append(vars, TemplateVar{Name: name})
+			}
+			return vars
+		}
+		for _, next := range lines[i+1:] {
+			if strings.TrimSpace(next) == "" {
+				continue
+			}
+			if next[0] != ' ' && next[0] != '\t' {
+				break
+			}
+			item := strings.TrimSpace(next)
+			item = strings.TrimSpace(strings.TrimPrefix(item, "- "))
+			name, def, hasDefault := strings.Cut(item, ":")
+			def = unquote(strings.TrimSpace(def))
+			vars = append(vars, TemplateVar{
+				Name:       strings.TrimSpace(name),
+				Default:    def,
+				HasDefault: hasDefault && def != "",
+			})
+		}
+		return vars
+	}
+	return nil
+}
+
+// ParseVars turns name=value pairs, as given to --var, into a map.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --var %q: use name=value", p)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+	return vars, nil
+}
+
+// RenderTemplate replaces {{name}} placeholders in b with vars, falling back
+// to the defaults in decl. Placeholders left without a value are an error.
+func RenderTemplate(b []byte, decl []TemplateVar, vars map[string]string) ([]byte, error) {
+	values := make(map[string]string, len(decl)+len(vars))
+	for _, v := range decl {
+		if v.HasDefault {
+			values[v.Name] = v.Default
+		}
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	var missing []string
+	for _, name := range TemplateVars(b) {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing template variables: %s (use --var name=value)", strings.Join(missing, ", "))
+	}
+	return templateVarRe.ReplaceAllFunc(b, func(m []byte) []byte {
+		return []byte(values[string(templateVarRe.FindSubmatch(m)[1])])
+	}), nil
+}
+
+// RenderEntry reads an entry like ReadEntry and fills in its template
+// variables. Entries that declare no vars are returned unchanged unless
+// vars are given, so literal braces in plain entries are left alone.
+// Collections render their members in order.
+func RenderEntry(store, name, rev string, vars map[string]string) ([]byte, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	return renderEntry(store, name, rev, vars, []string{includeKey(name, rev)})
+}
+
+// renderEntry renders name; stack holds the collections and includes being
+// rendered, for cycle detection.
+func renderEntry(store, name, rev string, vars map[string]string, stack []string) ([]byte, error) {
+	file, err := ReadEntryFileAt(store, name, rev)
+	if err != nil {
+		return nil, err
+	}
+	if IsCollection(file) {
+		return renderCollection(store, rev, file, vars, stack)
+	}
+	b, err := expandIncludes(store, StripFrontMatter(file), rev, stack)
+	if err != nil {
+		return nil, err
+	}
+	decl := DeclaredVars(file)
+	if len(decl) == 0 && len(vars) == 0 {
+		return b, nil
+	}
+	return RenderTemplate(b, decl, vars)
+}
@@ -0,0 +1,438 @@
+package app
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a model would see for a text.
+type Tokenizer interface {
+	Name() string
+	Count(text string) int
+}
+
+// Tokenizer names.
+const (
+	TokenizerApprox = "approx"
+	EncodingCL100K  = "cl100k_base"
+	EncodingO200K   = "o200k_base"
+)
+
+// modelEncodings maps model name prefixes to their BPE encoding. The
+// longest matching prefix wins. Models missing here use the approximation.
+var modelEncodings = map[string]string{
+	"gpt-4o":                 EncodingO200K,
+	"gpt-4.1":                EncodingO200K,
+	"gpt-4.5":                EncodingO200K,
+	"gpt-5":                  EncodingO200K,
+	"o1":                     EncodingO200K,
+	"o3":                     EncodingO200K,
+	"o4":                     EncodingO200K,
+	"chatgpt-4o":             EncodingO200K,
+	"gpt-4":                  EncodingCL100K,
+	"gpt-3.5-turbo":          EncodingCL100K,
+	"gpt-35-turbo":           EncodingCL100K,
+	"text-embedding-3":       EncodingCL100K,
+	"text-embedding-ada-002": EncodingCL100K,
+}
+
+// EncodingForModel returns the BPE encoding used by model, or "" if unknown.
+func EncodingForModel(model string) string {
+	model = strings.ToLower(model)
+	best := ""
+	for prefix := range modelEncodings {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return modelEncodings[best]
+}
+
+// TokenizerDir holds tiktoken rank files named <encoding>.tiktoken, such as
+// cl100k_base.tiktoken from openaipublic.blob.core.windows.net/encodings.
+func TokenizerDir() string {
+	base, _ := DefaultPaths()
+	return filepath.Join(base, "tokenizers")
+}
+
+// TokenizerFor picks the tokenizer for an entry: the 'tokenizer' config key
+// when set, else the encoding of the entry's 'model:' front matter or the
+// configured [llm] model. BPE encodings need their rank file in
+// TokenizerDir; without it the approximation is used.
+func TokenizerFor(entryFile []byte) Tokenizer {
+	return NewTokenizerPicker().For(entryFile)
+}
+
+// TokenizerPicker is TokenizerFor with the config read once, for callers
+// that measure many entries.
+type TokenizerPicker struct {
+	tokenizer string // the 'tokenizer' config key
+	model     string // the configured [llm] model
+}
+
+// NewTokenizerPicker reads the tokenizer settings from the config.
+func NewTokenizerPicker() TokenizerPicker {
+	return TokenizerPicker{tokenizer: ReadConfig().Tokenizer, model: GetLLMConfig().Model}
+}
+
+// For returns the tokenizer for an entry file; see TokenizerFor.
+func (p TokenizerPicker) For(entryFile []byte) Tokenizer {
+	name := p.tokenizer
+	if name == "" || name == "auto" {
+		model := p.model
+		if v, ok := FrontMatterValue(entryFile, "model"); ok && v != "" {
+			model = v
+		}
+		name = EncodingForModel(model)
+	}
+	if name == "" || name == TokenizerApprox {
+		return approxTokenizer{}
+	}
+	bpe, err := loadBPE(name)
+	if err != nil {
+		return approxTokenizer{missing: name}
+	}
+	return bpe
+}
+
+// ApproxFallback returns the BPE encoding tok stands in for because its
+// rank file is not installed, or "" if tok is what was asked for.
+func ApproxFallback(tok Tokenizer) string {
+	if a, ok := tok.(approxTokenizer); ok {
+		return a.missing
+	}
+	return ""
+}
+
+// approxTokenizer estimates tokens without a vocabulary: one token per six
+// characters of a word and one per punctuation mark. Common English words
+// are single tokens in the BPE encodings, so this errs slightly high.
+type approxTokenizer struct {
+	missing string // the encoding whose rank file was not found, if any
+}
+
+func (approxTokenizer) Name() string { return TokenizerApprox }
+
+func (approxTokenizer) Count(text string) int {
+	n := 0
+	word := 0
+	flush := func() {
+		if word > 0 {
+			n += (word + 5) / 6
+			word = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			n++
+		}
+	}
+	flush()
+	return n
+}
+
+// bpeTokenizer implements byte-pair encoding over a tiktoken rank table.
+type bpeTokenizer struct {
+	name  string
+	ranks map[string]int
+	split func(string) []string // the encoding's pretokenizer
+}
+
+var (
+	bpeMu    sync.Mutex
+	bpeCache = make(map[string]*bpeTokenizer)
+)
+
+// loadBPE reads <TokenizerDir>/<name>.tiktoken, where each line holds a
+// base64-encoded token and its rank.
+func loadBPE(name string) (*bpeTokenizer, error) {
+	bpeMu.Lock()
+	defer bpeMu.Unlock()
+	if t, ok := bpeCache[name]; ok {
+		return t, nil
+	}
+
+	f, err := os.Open(filepath.Join(TokenizerDir(), name+".tiktoken"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranks := make(map[string]int)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		token, rank, ok := strings.Cut(strings.TrimSpace(sc.Text()), " ")
+		if !ok {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token in %s: %w", name, err)
+		}
+		r, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank in %s: %w", name, err)
+		}
+		ranks[string(b)] = r
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	t := &bpeTokenizer{name: name, ranks: ranks, split: pretokenizeCL100K}
+	if name == EncodingO200K {
+		t.split = pretokenizeO200K
+	}
+	bpeCache[name] = t
+	return t, nil
+}
+
+func (t *bpeTokenizer) Name() string { return t.name }
+
+func (t *bpeTokenizer) Count(text string) int {
+	n := 0
+	for _, piece := range t.split(text) {
+		n += t.countPiece(piece)
+	}
+	return n
+}
+
+// countPiece merges the bytes of piece by ascending rank until no adjacent
+// pair forms a known token, and returns the number of parts left.
+func (t *bpeTokenizer) countPiece(piece string) int {
+	if _, ok := t.ranks[piece]; ok {
+		return 1
+	}
+	parts := make([]string, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			if r, ok := t.ranks[parts[i]+parts[i+1]]; ok && (best < 0 || r < bestRank) {
+				best, bestRank = i, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return len(parts)
+}
+
+// pretokenizeCL100K splits text the way the cl100k_base pattern does before
+// BPE: contractions, letter runs with one leading non-letter, numbers of up
+// to three digits, punctuation runs, newlines and other whitespace.
+func pretokenizeCL100K(text string) []string {
+	var pieces []string
+	rs := []rune(text)
+	isLetter := func(r rune) bool { return unicode.IsLetter(r) }
+	isNumber := func(r rune) bool { return unicode.IsNumber(r) }
+	isNewline := func(r rune) bool { return r == '\r' || r == '\n' }
+
+	for i := 0; i < len(rs); {
+		start := i
+		r := rs[i]
+		switch {
+		case r == '\'' && i+1 < len(rs) && contractionLen(rs[i:]) > 0:
+			i += contractionLen(rs[i:])
+		case isLetter(r) || (!isNewline(r) && !isNumber(r) && i+1 < len(rs) && isLetter(rs[i+1]) && !isLetter(r)):
+			i++
+			for i < len(rs) && isLetter(rs[i]) {
+				i++
+			}
+		case isNumber(r):
+			for i < len(rs) && isNumber(rs[i]) && i-start < 3 {
+				i++
+			}
+		case !unicode.IsSpace(r) || (r == ' ' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && !isLetter(rs[i+1]) && !isNumber(rs[i+1])):
+			if r == ' ' {
+				i++
+			}
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !isLetter(rs[i]) && !isNumber(rs[i]) {
+				i++
+			}
+			for i < len(rs) && isNewline(rs[i]) {
+				i++
+			}
+		default:
+			i = whitespaceEnd(rs, i)
+		}
+		if i == start {
+			i++
+		}
+		pieces = append(pieces, string(rs[start:i]))
+	}
+	return pieces
+}
+
+// pretokenizeO200K splits text the way the o200k_base pattern does before
+// BPE. Unlike cl100k_base, letter runs split before an uppercase letter that
+// follows lowercase ones ("HelloWorld" is "Hello" "World"), contractions
+// stay attached to their word, and punctuation runs absorb a trailing '/'.
+func pretokenizeO200K(text string) []string {
+	var pieces []string
+	rs := []rune(text)
+	isNewline := func(r rune) bool { return r == '\r' || r == '\n' }
+	isPrefix := func(r rune) bool { return !isNewline(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r) }
+
+	for i := 0; i < len(rs); {
+		start := i
+		r := rs[i]
+		end := -1
+		if isPrefix(r) {
+			end = o200kWordEnd(rs, i+1)
+		}
+		if end < 0 {
+			end = o200kWordEnd(rs, i)
+		}
+		switch {
+		case end >= 0:
+			i = end
+		case unicode.IsNumber(r):
+			for i < len(rs) && unicode.IsNumber(rs[i]) && i-start < 3 {
+				i++
+			}
+		case !unicode.IsSpace(r) || (r == ' ' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) && !unicode.IsLetter(rs[i+1]) && !unicode.IsNumber(rs[i+1])):
+			if r == ' ' {
+				i++
+			}
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !unicode.IsLetter(rs[i]) && !unicode.IsNumber(rs[i]) {
+				i++
+			}
+			for i < len(rs) && (isNewline(rs[i]) || rs[i] == '/') {
+				i++
+			}
+		default:
+			i = whitespaceEnd(rs, i)
+		}
+		if i == start {
+			i++
+		}
+		pieces = append(pieces, string(rs[start:i]))
+	}
+	return pieces
+}
+
+// o200kWordEnd matches the o200k_base word patterns at rs[i:]: a run of
+// upper or title case letters followed by lowercase ones, with an optional
+// contraction. It returns the end of the match, or -1.
+func o200kWordEnd(rs []rune, i int) int {
+	upper := func(r rune) bool {
+		return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+	}
+	lower := func(r rune) bool {
+		return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+	}
+	j := i
+	for j < len(rs) && upper(rs[j]) {
+		j++
+	}
+	k := j
+	for k < len(rs) && lower(rs[k]) {
+		k++
+	}
+	if k == i {
+		return -1
+	}
+	return k + contractionLen(rs[k:])
+}
+
+// whitespaceEnd returns the end of the whitespace piece starting at rs[i]:
+// a run ending in newlines is one piece; otherwise the last space is left
+// to prefix the following word.
+func whitespaceEnd(rs []rune, i int) int {
+	j := i
+	for j < len(rs) && unicode.IsSpace(rs[j]) {
+		j++
+	}
+	lastNL := -1
+	for k := i; k < j; k++ {
+		if rs[k] == '\r' || rs[k] == '\n' {
+			lastNL = k
+		}
+	}
+	switch {
+	case lastNL >= 0:
+		return lastNL + 1
+	case j < len(rs) && j-i > 1:
+		return j - 1
+	}
+	return j
+}
+
+// contractionLen returns the length of an English contraction suffix such
+// as 's or 'll at the start of rs, or 0.
+func contractionLen(rs []rune) int {
+	for _, c := range []string{"'ll", "'ve", "'re", "'s", "'t", "'m", "'d"} {
+		if len(rs) >= utf8.RuneCountInString(c) && strings.EqualFold(string(rs[:utf8.RuneCountInString(c)]), c) {
+			return utf8.RuneCountInString(c)
+		}
+	}
+	return 0
+}
+
+// TextStats are the size measures reported by 'get --stats' and 'ls -l'.
+type TextStats struct {
+	Chars     int
+	Words     int
+	Tokens    int
+	Tokenizer string
+	// Fallback names the encoding the approximation stands in for, when
+	// its rank file is not installed.
+	Fallback string
+}
+
+// MeasureText counts characters, words and tokens in text.
+func MeasureText(text string, tok Tokenizer) TextStats {
+	return TextStats{
+		Chars:     utf8.RuneCountInString(text),
+		Words:     len(strings.Fields(text)),
+		Tokens:    tok.Count(text),
+		Tokenizer: tok.Name(),
+		Fallback:  ApproxFallback(tok),
+	}
+}
+
+// CheckTokenBudget returns an error if the entry content b, with includes
+// expanded or, for a collection, its members rendered, exceeds the
+// 'max_tokens' set in its front matter.
+func CheckTokenBudget(store, name string, b []byte) error {
+	v, ok := FrontMatterValue(b, "max_tokens")
+	if !ok || v == "" {
+		return nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return fmt.Errorf("invalid max_tokens %q: use a positive number", v)
+	}
+	body := StripFrontMatter(b)
+	if IsCollection(b) {
+		if rendered, err := renderCollection(store, "", b, nil, []string{name}); err == nil {
+			body = rendered
+		}
+	} else if expanded, err := expandIncludes(store, body, "", []string{name}); err == nil {
+		body = expanded
+	}
+	tok := TokenizerFor(b)
+	if n := tok.Count(string(body)); n > limit {
+		return fmt.Errorf("entry is %d tokens (%s), over max_tokens %d", n, tok.Name(), limit)
+	}
+	return nil
+}
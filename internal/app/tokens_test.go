@@ -0,0 +1,107 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPretokenize(t *testing.T) {
+	tests := []struct {
+		text  string
+		cl100 []string
+		o200  []string
+	}{
+		{"Hello world", []string{"Hello", " world"}, []string{"Hello", " world"}},
+		{"HelloWorld", []string{"HelloWorld"}, []string{"Hello", "World"}},
+		{"JSONParser", []string{"JSONParser"}, []string{"JSONParser"}},
+		{"I'm here", []string{"I", "'m", " here"}, []string{"I'm", " here"}},
+		{"don't", []string{"don", "'t"}, []string{"don't"}},
+		{"1234567", []string{"123", "456", "7"}, []string{"123", "456", "7"}},
+		{"a  b", []string{"a", " ", " b"}, []string{"a", " ", " b"}},
+		{"x\n\ny", []string{"x", "\n\n", "y"}, []string{"x", "\n\n", "y"}},
+		{"path/to", []string{"path", "/to"}, []string{"path", "/to"}},
+		{"ok!!\n", []string{"ok", "!!\n"}, []string{"ok", "!!\n"}},
+		{"a: //b", []string{"a", ":", " //", "b"}, []string{"a", ":", " //", "b"}},
+		{"end  ", []string{"end", "  "}, []string{"end", "  "}},
+	}
+	for _, tt := range tests {
+		if got := pretokenizeCL100K(tt.text); !slices.Equal(got, tt.cl100) {
+			t.Errorf("cl100k_base %q: got %q, want %q", tt.text, got, tt.cl100)
+		}
+		if got := pretokenizeO200K(tt.text); !slices.Equal(got, tt.o200) {
+			t.Errorf("o200k_base %q: got %q, want %q", tt.text, got, tt.o200)
+		}
+	}
+}
+
+func TestBPECount(t *testing.T) {
+	ranks := make(map[string]int)
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+	for i, tok := range []string{"he", "ll", "hell", "hello", " w", "bc", "ab"} {
+		ranks[tok] = 256 + i
+	}
+	tok := &bpeTokenizer{name: EncodingCL100K, ranks: ranks, split: pretokenizeCL100K}
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello", 1},
+		// "hello" | " w" "o" "r" "l" "d"
+		{"hello world", 6},
+		// "he" and "ll" merge to "hell"; "x" has no merge with it
+		{"hellx", 2},
+		// "bc" ranks below "ab", so "abc" is "a" "bc"
+		{"abc", 2},
+		// é is two bytes with no merge: "h" "\xc3" "\xa9" "ll" "o"
+		{"héllo", 5},
+	}
+	for _, tt := range tests {
+		if got := tok.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestBPEKnownCounts checks counts published for the real encodings. The
+// rank files are not part of the repo, so it only runs where they are
+// installed in TokenizerDir.
+func TestBPEKnownCounts(t *testing.T) {
+	tests := []struct {
+		encoding string
+		text     string
+		want     int
+	}{
+		{EncodingCL100K, "hello world", 2},
+		{EncodingO200K, "hello world", 2},
+	}
+	for _, tt := range tests {
+		if _, err := os.Stat(filepath.Join(TokenizerDir(), tt.encoding+".tiktoken")); err != nil {
+			t.Logf("skipping %s: rank file not installed", tt.encoding)
+			continue
+		}
+		tok, err := loadBPE(tt.encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tok.Count(tt.text); got != tt.want {
+			t.Errorf("%s Count(%q) = %d, want %d", tt.encoding, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestApproxFallback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tok := TokenizerPicker{tokenizer: EncodingO200K}.For(nil)
+	if tok.Name() != TokenizerApprox || ApproxFallback(tok) != EncodingO200K {
+		t.Fatalf("missing rank file should fall back to the approximation: %s, %q", tok.Name(), ApproxFallback(tok))
+	}
+	if got := ApproxFallback(TokenizerPicker{tokenizer: TokenizerApprox}.For(nil)); got != "" {
+		t.Fatalf("an explicit approx tokenizer is not a fallback: %q", got)
+	}
+}
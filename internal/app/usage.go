@@ -0,0 +1,173 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage event kinds.
+const (
+	UsageGet = "get"
+	UsageCp  = "cp"
+	UsageRun = "run"
+	UsageAsk = "ask"
+)
+
+// usageFileName is the usage log under the pea base directory, outside the
+// store so it is never committed.
+const usageFileName = "usage.tsv"
+
+// usageMaxBytes and usageKeepEvents bound the usage log: once it grows past
+// usageMaxBytes it is rewritten with only the most recent events.
+const (
+	usageMaxBytes   = 1 << 20
+	usageKeepEvents = 5000
+)
+
+// UsageStats summarizes how often and how recently an entry was retrieved.
+type UsageStats struct {
+	Name  string
+	Count int
+	Last  time.Time
+}
+
+func usagePath() string {
+	base, _ := DefaultPaths()
+	return filepath.Join(base, usageFileName)
+}
+
+// UsageTrackingEnabled reports whether retrievals are recorded. It is on
+// unless 'track_usage = false' is set in the config.
+func UsageTrackingEnabled() bool {
+	conf := ReadConfig()
+	return conf.TrackUsage == nil || *conf.TrackUsage
+}
+
+// RecordUsage appends a retrieval event for name in store to the usage log.
+// Appends and compaction hold the usage lock, so an event written by another
+// pea process is never dropped when the log is rewritten.
+func RecordUsage(store, name, kind string) error {
+	if !UsageTrackingEnabled() {
+		return nil
+	}
+	p := usagePath()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	lock, _, err := lockFile(p+".lock", time.Now().Add(lockTimeout))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = unlockFile(lock)
+		lock.Close()
+	}()
+
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%d\t%s\t%s\t%s\n", time.Now().Unix(), kind, store, name)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(p); err == nil && info.Size() > usageMaxBytes {
+		return compactUsage(p)
+	}
+	return nil
+}
+
+// compactUsage keeps only the most recent usageKeepEvents events. The
+// caller holds the usage lock.
+func compactUsage(p string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if len(lines) <= usageKeepEvents {
+		return nil
+	}
+	return WriteFileAtomic(p, []byte(strings.Join(lines[len(lines)-usageKeepEvents:], "")), 0o600)
+}
+
+// LoadUsage returns usage statistics for the entries of store, keyed by name.
+func LoadUsage(store string) (map[string]UsageStats, error) {
+	stats := make(map[string]UsageStats)
+	f, err := os.Open(usagePath())
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Split(sc.Text(), "\t")
+		if len(fields) != 4 || fields[2] != store {
+			continue
+		}
+		ts, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		s := stats[fields[3]]
+		s.Name = fields[3]
+		s.Count++
+		if t := time.Unix(ts, 0); t.After(s.Last) {
+			s.Last = t
+		}
+		stats[fields[3]] = s
+	}
+	return stats, sc.Err()
+}
+
+// Entry orderings accepted by SortEntries.
+const (
+	SortName     = "name"
+	SortRecent   = "recent"
+	SortFrequent = "frequent"
+)
+
+// SortEntries orders names in place by name, most recently used or most
+// frequently used. Ties and never-used entries fall back to name order.
+func SortEntries(names []string, by string, usage map[string]UsageStats) error {
+	var less func(a, b UsageStats) bool
+	switch by {
+	case "", SortName:
+		sort.Strings(names)
+		return nil
+	case SortRecent:
+		less = func(a, b UsageStats) bool { return a.Last.After(b.Last) }
+	case SortFrequent:
+		less = func(a, b UsageStats) bool {
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Last.After(b.Last)
+		}
+	default:
+		return fmt.Errorf("invalid sort %q: use name, recent or frequent", by)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := usage[names[i]], usage[names[j]]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return names[i] < names[j]
+	})
+	return nil
+}
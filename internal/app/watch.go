@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long WatchEntry waits for writes to settle
+// before reporting a change; editors often save in several steps.
+const DefaultWatchDebounce = 150 * time.Millisecond
+
+// WatchedEntries returns the entries whose content affects how name
+// renders: the entry itself and every include it reaches that follows the
+// working tree. Includes pinned to a revision never change and are left out;
+// missing ones are kept so creating them triggers a re-render.
+func WatchedEntries(store, name string) (map[string]bool, error) {
+	root, err := IncludeGraph(store, name, "")
+	if err != nil {
+		return nil, err
+	}
+	watched := map[string]bool{}
+	var walk func(n IncludeNode)
+	walk = func(n IncludeNode) {
+		if n.Rev != "" || watched[n.Name] {
+			return
+		}
+		watched[n.Name] = true
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(root)
+	return watched, nil
+}
+
+// WatchEntry calls onChange whenever name or one of its includes changes on
+// disk, until ctx is done. The store directory is watched rather than the
+// files themselves so saves that replace a file (write and rename) are seen.
+// The set of includes is recomputed after every change.
+func WatchEntry(ctx context.Context, store, name string, debounce time.Duration, onChange func()) error {
+	watched, err := WatchedEntries(store, name)
+	if err != nil {
+		return err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := w.Add(store); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			if entry, ok := entryNameFromFile(filepath.Base(ev.Name)); ok && watched[entry] {
+				timer.Reset(debounce)
+			}
+		case <-timer.C:
+			if next, err := WatchedEntries(store, name); err == nil {
+				watched = next
+			}
+			onChange()
+		}
+	}
+}
+
+// WatchStore calls onChange whenever a file anywhere in the store changes,
+// once writes have settled for debounce, until ctx is done. Directories
+// created later are watched as they appear; .git and other dot files,
+// including the store lock, are ignored.
+func WatchStore(ctx context.Context, store string, debounce time.Duration, onChange func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := watchTree(w, store); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod || strings.HasPrefix(filepath.Base(ev.Name), ".") {
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					_ = watchTree(w, ev.Name)
+				}
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+// watchTree adds dir and every directory below it to w, skipping dot
+// directories such as .git.
+func watchTree(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.Add(p)
+	})
+}
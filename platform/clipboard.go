@@ -0,0 +1,282 @@
+package platform
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Clipboard provider names accepted by NewClipboard.
+const (
+	ClipboardAuto   = "auto"
+	ClipboardNative = "native"
+	ClipboardOSC52  = "osc52"
+	ClipboardWLCopy = "wl-copy"
+	ClipboardXClip  = "xclip"
+	ClipboardXSel   = "xsel"
+	ClipboardTmux   = "tmux"
+	ClipboardFake   = "fake"
+)
+
+// ErrReadUnsupported is returned by providers that can only write.
+var ErrReadUnsupported = errors.New("clipboard provider cannot read")
+
+// NewClipboard returns the clipboard provider with the given name. "auto"
+// returns the chain of providers detected from the environment.
+func NewClipboard(name string) (Clipboard, error) {
+	switch name {
+	case ClipboardAuto:
+		return newChainClipboard(DetectClipboards()), nil
+	case ClipboardNative:
+		return &realClipboard{}, nil
+	case ClipboardOSC52:
+		return &osc52Clipboard{}, nil
+	case ClipboardWLCopy:
+		return &commandClipboard{
+			name:     name,
+			copyCmd:  []string{"wl-copy"},
+			pasteCmd: []string{"wl-paste", "--no-newline"},
+		}, nil
+	case ClipboardXClip:
+		return &commandClipboard{
+			name:     name,
+			copyCmd:  []string{"xclip", "-selection", "clipboard", "-in"},
+			pasteCmd: []string{"xclip", "-selection", "clipboard", "-out"},
+		}, nil
+	case ClipboardXSel:
+		return &commandClipboard{
+			name:     name,
+			copyCmd:  []string{"xsel", "--clipboard", "--input"},
+			pasteCmd: []string{"xsel", "--clipboard", "--output"},
+		}, nil
+	case ClipboardTmux:
+		return &commandClipboard{
+			name:     name,
+			copyCmd:  []string{"tmux", "load-buffer", "-w", "-"},
+			pasteCmd: []string{"tmux", "save-buffer", "-"},
+		}, nil
+	case ClipboardFake:
+		return &fakeClipboard{}, nil
+	}
+	return nil, fmt.Errorf("unknown clipboard %q: use auto, native, osc52, wl-copy, xclip, xsel or tmux", name)
+}
+
+// DetectClipboards returns clipboard provider names in the order they should
+// be tried for the current environment. Remote sessions prefer OSC 52 since
+// the local display tools would copy on the wrong machine.
+func DetectClipboards() []string {
+	remote := os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+	var names []string
+	if remote {
+		names = append(names, ClipboardOSC52)
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		names = append(names, ClipboardNative)
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			names = append(names, ClipboardWLCopy)
+		}
+		if os.Getenv("DISPLAY") != "" {
+			names = append(names, ClipboardXClip, ClipboardXSel, ClipboardNative)
+		}
+	}
+	if os.Getenv("TMUX") != "" {
+		names = append(names, ClipboardTmux)
+	}
+	if !remote {
+		names = append(names, ClipboardOSC52)
+	}
+	return names
+}
+
+// chainClipboard tries each provider in order and uses the first that works.
+type chainClipboard struct {
+	names      []string
+	providers  []Clipboard
+	ready      []Clipboard
+	readyNames []string
+	// written is the provider that accepted the last WriteText.
+	written     Clipboard
+	writtenName string
+}
+
+func newChainClipboard(names []string) *chainClipboard {
+	c := &chainClipboard{names: names}
+	for _, n := range names {
+		if p, err := NewClipboard(n); err == nil {
+			c.providers = append(c.providers, p)
+		}
+	}
+	return c
+}
+
+func (c *chainClipboard) Init() error {
+	c.ready, c.readyNames = nil, nil
+	var errs []error
+	for i, p := range c.providers {
+		if err := p.Init(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.names[i], err))
+			continue
+		}
+		c.ready = append(c.ready, p)
+		c.readyNames = append(c.readyNames, c.names[i])
+	}
+	if len(c.ready) == 0 {
+		return fmt.Errorf("no clipboard provider available (tried %s): %w", strings.Join(c.names, ", "), errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c *chainClipboard) WriteText(s string) error {
+	var errs []error
+	for i, p := range c.ready {
+		err := p.WriteText(s)
+		if err == nil {
+			c.written, c.writtenName = p, c.readyNames[i]
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return errors.New("clipboard not initialized")
+	}
+	return errors.Join(errs...)
+}
+
+// ReadText reads from the provider that took the last write, or else from
+// the first provider that supports reading. Providers earlier in the chain
+// are the ones WriteText prefers, so this sees what we wrote unless that
+// provider is write-only.
+func (c *chainClipboard) ReadText() (string, error) {
+	if c.written != nil {
+		return c.written.ReadText()
+	}
+	for _, p := range c.ready {
+		s, err := p.ReadText()
+		if errors.Is(err, ErrReadUnsupported) {
+			continue
+		}
+		return s, err
+	}
+	return "", ErrReadUnsupported
+}
+
+// WrittenBy returns the name of the provider that accepted the last
+// WriteText on cb, or "" if nothing was written through a chain yet.
+func WrittenBy(cb Clipboard) string {
+	switch c := cb.(type) {
+	case *chainClipboard:
+		return c.writtenName
+	case *osc52Clipboard:
+		return ClipboardOSC52
+	case *commandClipboard:
+		return c.name
+	case *realClipboard:
+		return ClipboardNative
+	case *fakeClipboard:
+		return ClipboardFake
+	}
+	return ""
+}
+
+// CanReadBack reports whether the provider that accepted the last
+// WriteText on cb can read the clipboard, which clearing it later relies
+// on. Write-only providers such as OSC 52 cannot.
+func CanReadBack(cb Clipboard) bool {
+	switch c := cb.(type) {
+	case *chainClipboard:
+		return c.written != nil && CanReadBack(c.written)
+	case *osc52Clipboard:
+		return false
+	}
+	return true
+}
+
+// commandClipboard pipes text into an external tool such as wl-copy or xclip.
+type commandClipboard struct {
+	name     string
+	copyCmd  []string
+	pasteCmd []string
+}
+
+func (c *commandClipboard) Init() error {
+	if _, err := exec.LookPath(c.copyCmd[0]); err != nil {
+		return fmt.Errorf("%s not found", c.copyCmd[0])
+	}
+	return nil
+}
+
+// WriteText runs the copy command. xclip, xsel and wl-copy fork a process
+// that keeps serving the selection, so stdout is discarded and stderr goes
+// to a file: a pipe would stay open and block Wait until the selection is
+// replaced.
+func (c *commandClipboard) WriteText(s string) error {
+	stderr, err := os.CreateTemp("", "pea-clipboard-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+
+	cmd := exec.Command(c.copyCmd[0], c.copyCmd[1:]...)
+	cmd.Stdin = strings.NewReader(s)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		msg, _ := os.ReadFile(stderr.Name())
+		return fmt.Errorf("%s failed: %w: %s", c.name, err, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (c *commandClipboard) ReadText() (string, error) {
+	cmd := exec.Command(c.pasteCmd[0], c.pasteCmd[1:]...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", c.pasteCmd[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// osc52Clipboard asks the terminal emulator to set the clipboard using the
+// OSC 52 escape sequence, which also works over SSH. The sequence goes to
+// the controlling terminal; without one the write fails so the chain moves
+// on.
+type osc52Clipboard struct{}
+
+// openTerminal opens the controlling terminal for OSC 52 sequences. Headless
+// runs replace it with openFakeTerminal.
+var openTerminal = func() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+func (o *osc52Clipboard) Init() error { return nil }
+
+func (o *osc52Clipboard) WriteText(s string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		// tmux only forwards escape sequences wrapped in a DCS passthrough.
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	tty, err := openTerminal()
+	if err != nil {
+		return fmt.Errorf("no terminal to send OSC 52 to: %w", err)
+	}
+	defer tty.Close()
+	_, err = io.WriteString(tty, seq)
+	return err
+}
+
+// ReadText is unsupported: reading via OSC 52 needs a terminal round trip
+// that most emulators disable.
+func (o *osc52Clipboard) ReadText() (string, error) {
+	return "", ErrReadUnsupported
+}
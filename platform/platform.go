@@ -5,6 +5,8 @@
 package platform
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -17,6 +19,9 @@ import (
 type Clipboard interface {
 	Init() error
 	WriteText(string) error
+	// ReadText returns the current clipboard text. Providers that cannot read
+	// the clipboard (such as OSC 52) return ErrReadUnsupported.
+	ReadText() (string, error)
 }
 
 // Browser abstracts opening files/URLs with the OS default handler.
@@ -25,8 +30,9 @@ type Browser interface {
 }
 
 var (
-	// ClipboardImpl is the implementation used by the application. It may be
-	// swapped for a fake in tests by setting PEA_HEADLESS in the environment.
+	// ClipboardImpl is the implementation used by the application: a chain of
+	// providers detected from the environment. It may be swapped for a fake in
+	// tests by setting PEA_HEADLESS in the environment.
 	ClipboardImpl Clipboard
 	// BrowserImpl is the implementation used by the application.
 	BrowserImpl Browser
@@ -44,11 +50,12 @@ func initImpls() {
 		// In test/headless mode, use fakes that do not require OS services.
 		ClipboardImpl = &fakeClipboard{}
 		BrowserImpl = &fakeBrowser{}
+		openTerminal = openFakeTerminal
 		return
 	}
 
 	// Default real implementations
-	ClipboardImpl = &realClipboard{}
+	ClipboardImpl = newChainClipboard(DetectClipboards())
 	BrowserImpl = &realBrowser{}
 }
 
@@ -64,6 +71,10 @@ func (r *realClipboard) WriteText(s string) error {
 	return nil
 }
 
+func (r *realClipboard) ReadText() (string, error) {
+	return string(clipboardpkg.Read(clipboardpkg.FmtText)), nil
+}
+
 // realBrowser is an adapter to github.com/pkg/browser.
 type realBrowser struct{}
 
@@ -78,14 +89,7 @@ func (f *fakeClipboard) Init() error { return nil }
 
 func (f *fakeClipboard) WriteText(s string) error {
 
-	p := os.Getenv("PEA_FAKE_CLIP_FILE")
-
-	if p == "" {
-
-		// default to temp file in current working directory
-		tmp := os.TempDir()
-		p = filepath.Join(tmp, "pea_fake_clipboard")
-	}
+	p := fakeClipboardPath()
 
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
@@ -95,6 +99,33 @@ func (f *fakeClipboard) WriteText(s string) error {
 	return os.WriteFile(p, []byte(s), 0o644)
 }
 
+func (f *fakeClipboard) ReadText() (string, error) {
+	b, err := os.ReadFile(fakeClipboardPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return string(b), err
+}
+
+func fakeClipboardPath() string {
+	if p := os.Getenv("PEA_FAKE_CLIP_FILE"); p != "" {
+		return p
+	}
+	// default to temp file in current working directory
+	return filepath.Join(os.TempDir(), "pea_fake_clipboard")
+}
+
+// openFakeTerminal stands in for the controlling terminal: what pea sends to
+// it is appended to PEA_FAKE_TTY_FILE. Without that variable there is no
+// terminal, as in a detached session.
+func openFakeTerminal() (io.WriteCloser, error) {
+	p := os.Getenv("PEA_FAKE_TTY_FILE")
+	if p == "" {
+		return nil, errors.New("PEA_FAKE_TTY_FILE is not set")
+	}
+	return os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
 // fakeBrowser does a no-op for headless tests.
 type fakeBrowser struct{}
 